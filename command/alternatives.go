@@ -0,0 +1,208 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mitchellh/cli"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const XBPS_ALTERNATIVES_PATH = "/usr/bin/xbps-alternatives"
+
+// Alternatives command stub
+type AlternativesCommand struct {
+	PkgCommand
+	Group string
+}
+
+// Symlink managed by an alternatives group
+type AltLink struct {
+	Link   string `json:"link"`
+	Target string `json:"target"`
+	Actual string `json:"actual,omitempty"`
+	Status string `json:"status"`
+}
+
+// Provider of an alternatives group
+type AltProvider struct {
+	Package string    `json:"package"`
+	Version string    `json:"version"`
+	Active  bool      `json:"active"`
+	Links   []AltLink `json:"links"`
+}
+
+// Alternatives group with its providers in priority order
+type AltGroup struct {
+	Name      string        `json:"name"`
+	Active    string        `json:"active"`
+	Providers []AltProvider `json:"providers"`
+	Broken    int           `json:"broken"`
+}
+
+func (c *AlternativesCommand) Commands(appName string, ui cli.Ui, debug bool) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"alternatives list": func() (cli.Command, error) {
+			return &AlternativesListCommand{
+				AlternativesCommand: AlternativesCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void alternatives list",
+							SynopsisText: "List alternatives groups",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "json",
+									Boolean:     true,
+									Description: "Output as JSON"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"alternatives set": func() (cli.Command, error) {
+			return &AlternativesSetCommand{
+				AlternativesCommand: AlternativesCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void alternatives set GROUP PKG",
+							SynopsisText: "Switch the provider of an alternatives group",
+							Flags:        c.flags(),
+							UI:           ui,
+							AppName:      appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"alternatives show": func() (cli.Command, error) {
+			return &AlternativesShowCommand{
+				AlternativesCommand: AlternativesCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void alternatives show GROUP",
+							SynopsisText: "Show providers and links of an alternatives group",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "json",
+									Boolean:     true,
+									Description: "Output as JSON"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+	}
+}
+
+func (c *AlternativesCommand) Init(args []string, group bool) (ParsedCli, error) {
+	fmtOpts, err := c.PkgCommand.Init(args, false)
+	if err != nil {
+		return fmtOpts, err
+	}
+	if group {
+		if len(fmtOpts.Args) < 1 {
+			return fmtOpts, errors.New("Alternatives group name required!")
+		}
+		c.Group = fmtOpts.Args[0]
+	}
+	return fmtOpts, nil
+}
+
+// Builds all alternatives groups registered in the pkgdb. The first
+// provider of a group is the active one.
+func (c *AlternativesCommand) AltGroups(db *PkgDB) []*AltGroup {
+	groups := []*AltGroup{}
+	names := []string{}
+	for name := range db.Alternatives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		groups = append(groups, c.altGroup(db, name))
+	}
+	return groups
+}
+
+func (c *AlternativesCommand) AltGroup(db *PkgDB, name string) (*AltGroup, error) {
+	if _, ok := db.Alternatives[name]; !ok {
+		return nil, errors.New(fmt.Sprintf(
+			"Unknown alternatives group `%s`", name))
+	}
+	return c.altGroup(db, name), nil
+}
+
+func (c *AlternativesCommand) altGroup(db *PkgDB, name string) *AltGroup {
+	group := &AltGroup{Name: name, Providers: []AltProvider{}}
+	for i, pkgName := range db.Alternatives[name] {
+		provider := AltProvider{Package: pkgName, Active: i == 0, Links: []AltLink{}}
+		if i == 0 {
+			group.Active = pkgName
+		}
+		if pkg, ok := db.Packages[pkgName]; ok {
+			provider.Version = pkg.Version
+			for _, entry := range pkg.Alternatives[name] {
+				link := c.altLink(entry, provider.Active)
+				if link.Status != "ok" && link.Status != "inactive" {
+					group.Broken++
+				}
+				provider.Links = append(provider.Links, link)
+			}
+		}
+		group.Providers = append(group.Providers, provider)
+	}
+	return group
+}
+
+// Parses a `link:target` alternatives entry and checks the link on
+// disk. Links without a directory are created next to their target and
+// relative targets are relative to the link directory, as done by xbps.
+func (c *AlternativesCommand) altLink(entry string, active bool) AltLink {
+	parts := strings.SplitN(entry, ":", 2)
+	link := AltLink{Link: parts[0]}
+	if len(parts) == 2 {
+		link.Target = parts[1]
+	}
+	if !strings.Contains(link.Link, "/") {
+		link.Link = filepath.Join(filepath.Dir(link.Target), link.Link)
+	}
+	if !active {
+		link.Status = "inactive"
+		return link
+	}
+	path := filepath.Join(c.RootDir, link.Link)
+	actual, err := os.Readlink(path)
+	if err != nil {
+		link.Status = "missing"
+		if _, statErr := os.Lstat(path); statErr == nil {
+			link.Status = "not-symlink"
+		}
+		return link
+	}
+	link.Actual = actual
+	if c.linkTarget(link.Link, actual) != c.linkTarget(link.Link, link.Target) {
+		link.Status = "wrong-target"
+		return link
+	}
+	if _, err := os.Stat(filepath.Join(c.RootDir, c.linkTarget(link.Link, actual))); err != nil {
+		link.Status = "broken"
+		return link
+	}
+	link.Status = "ok"
+	return link
+}
+
+func (c *AlternativesCommand) linkTarget(link string, target string) string {
+	if filepath.IsAbs(target) {
+		return filepath.Clean(target)
+	}
+	return filepath.Join(filepath.Dir(link), target)
+}
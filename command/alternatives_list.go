@@ -0,0 +1,53 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type AlternativesListCommand struct {
+	AlternativesCommand
+}
+
+func (c *AlternativesListCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup alternatives command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	groups := c.AltGroups(db)
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(groups); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	if len(groups) == 0 {
+		c.UI.Info("No alternatives groups registered")
+		return 0
+	}
+	rows := [][]string{}
+	for _, group := range groups {
+		providers := []string{}
+		for _, provider := range group.Providers {
+			providers = append(providers, provider.Package)
+		}
+		status := "ok"
+		if group.Broken > 0 {
+			status = fmt.Sprintf("%d broken", group.Broken)
+		}
+		rows = append(rows, []string{group.Name, group.Active, strings.Join(providers, ","), status})
+	}
+	c.outputTable([]string{"GROUP", "ACTIVE", "PROVIDERS", "STATUS"}, rows)
+	return 0
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+type AlternativesSetCommand struct {
+	AlternativesCommand
+}
+
+func (c *AlternativesSetCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args, true)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup alternatives command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 2 {
+		c.UI.Error("Alternatives group and package name required!")
+		return exitCode
+	}
+	pkgName := cOpts.Args[1]
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	group, err := c.AltGroup(db, c.Group)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	providers := []string{}
+	for _, provider := range group.Providers {
+		providers = append(providers, provider.Package)
+	}
+	if !c.contains(providers, pkgName) {
+		c.UI.Error(fmt.Sprintf(
+			"Package `%s` does not provide alternatives group `%s`!", pkgName, c.Group))
+		return exitCode
+	}
+	xArgs := append(c.xbpsRootArgs(), "-s", pkgName, "-g", c.Group)
+	if c.ExecuteCommand(exec.Command(XBPS_ALTERNATIVES_PATH, xArgs...)) != 0 {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to set alternatives group `%s` to `%s`!", c.Group, pkgName))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf(
+		"Alternatives group `%s` now provided by: %s", c.Group, pkgName))
+	return 0
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+)
+
+type AlternativesShowCommand struct {
+	AlternativesCommand
+}
+
+func (c *AlternativesShowCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, true)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup alternatives command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	group, err := c.AltGroup(db, c.Group)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(group); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	for _, provider := range group.Providers {
+		mark := "[-]"
+		if provider.Active {
+			mark = "[*]"
+		}
+		c.UI.Output(fmt.Sprintf("%s %s %s", mark, provider.Package, provider.Version))
+		rows := [][]string{}
+		for _, link := range provider.Links {
+			status := link.Status
+			if link.Status == "wrong-target" {
+				status = fmt.Sprintf("%s (-> %s)", status, link.Actual)
+			}
+			rows = append(rows, []string{"   ", link.Link, "->", link.Target, status})
+		}
+		if len(rows) > 0 {
+			c.outputTable(nil, rows)
+		}
+	}
+	if group.Broken > 0 {
+		c.UI.Warn(fmt.Sprintf(
+			"Group `%s` has %d broken links (run `%s alternatives set %s %s` to repair)",
+			group.Name, group.Broken, c.AppName, group.Name, group.Active))
+		return exitCode
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const ZSTD_PATH = "/usr/bin/zstd"
+const XZ_PATH = "/usr/bin/xz"
+
+var gzipMagic = []byte{0x1f, 0x8b}
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+var xzMagic = []byte{0xfd, 0x37, 0x7a, 0x58, 0x5a, 0x00}
+
+// Walks the entries of a (possibly compressed) tar archive. Gzip is
+// handled natively while zstd and xz are streamed through the system
+// tools.
+func (c *CoreCommand) walkArchive(path string, walkFn func(*tar.Header, io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	magic := make([]byte, 6)
+	n, _ := io.ReadFull(file, magic)
+	magic = magic[:n]
+	if _, err := file.Seek(0, 0); err != nil {
+		return err
+	}
+	var reader io.Reader
+	var cmd *exec.Cmd
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		reader = gz
+	case bytes.HasPrefix(magic, zstdMagic):
+		if cmd, reader, err = c.decompress(ZSTD_PATH, file); err != nil {
+			return err
+		}
+	case bytes.HasPrefix(magic, xzMagic):
+		if cmd, reader, err = c.decompress(XZ_PATH, file); err != nil {
+			return err
+		}
+	default:
+		reader = file
+	}
+	err = walkTar(path, reader, walkFn)
+	if cmd == nil {
+		return err
+	}
+	// Stop the decompressor when walking failed, otherwise let it
+	// finish writing so its exit status can be checked
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	io.Copy(ioutil.Discard, reader)
+	if err := cmd.Wait(); err != nil {
+		return errors.New(fmt.Sprintf(
+			"Failed to decompress archive using `%s`: %s", cmd.Path, err))
+	}
+	return nil
+}
+
+func walkTar(path string, reader io.Reader, walkFn func(*tar.Header, io.Reader) error) error {
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		if err := walkFn(header, archive); err != nil {
+			return err
+		}
+	}
+}
+
+// Reads regular file members from a tar archive. Only members
+// accepted by the filter are returned, keyed by their archive path.
+func (c *CoreCommand) readArchive(path string, filter func(string) bool) (map[string][]byte, error) {
+	members := map[string][]byte{}
+	err := c.walkArchive(path, func(header *tar.Header, r io.Reader) error {
+		if header.Typeflag != tar.TypeReg || !filter(header.Name) {
+			return nil
+		}
+		content, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		members[header.Name] = content
+		return nil
+	})
+	return members, err
+}
+
+// Reads the headers of all members of a tar archive, keyed by their
+// installation path
+func (c *CoreCommand) archiveHeaders(path string) (map[string]*tar.Header, error) {
+	headers := map[string]*tar.Header{}
+	err := c.walkArchive(path, func(header *tar.Header, r io.Reader) error {
+		headers[archiveMemberPath(header.Name)] = header
+		return nil
+	})
+	return headers, err
+}
+
+// Absolute installation path of a package archive member (`./etc/foo`)
+func archiveMemberPath(name string) string {
+	return "/" + strings.TrimPrefix(strings.TrimPrefix(name, "./"), "/")
+}
+
+// Starts the tool decompressing the input. The caller reads the
+// output and waits for the returned command.
+func (c *CoreCommand) decompress(tool string, input io.Reader) (*exec.Cmd, io.Reader, error) {
+	cmd := exec.Command(tool, "-dc")
+	cmd.Stdin = input
+	if c.Debug {
+		cmd.Stderr = os.Stderr
+	}
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, errors.New(fmt.Sprintf(
+			"Failed to decompress archive using `%s`: %s", tool, err))
+	}
+	return cmd, output, nil
+}
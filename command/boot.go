@@ -0,0 +1,215 @@
+package command
+
+import (
+	"fmt"
+	"github.com/mitchellh/cli"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const DRACUT_PATH = "/usr/bin/dracut"
+const DRACUT_CONFIG_PATH = "/etc/dracut.conf"
+const DRACUT_CONFIG_DIR_PATH = "/etc/dracut.conf.d"
+const DRACUT_LIB_CONFIG_DIR_PATH = "/usr/lib/dracut/dracut.conf.d"
+const GRUB_MKCONFIG_PATH = "/usr/bin/grub-mkconfig"
+const GRUB_CONFIG_PATH = "/boot/grub/grub.cfg"
+
+// Boot command stub
+type BootCommand struct {
+	KernelCommand
+}
+
+// Initramfs state of a kernel in /boot
+type BootImage struct {
+	Version   string `json:"version"`
+	Kernel    string `json:"kernel"`
+	Initramfs string `json:"initramfs"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+func (c *BootCommand) Commands(appName string, ui cli.Ui, debug bool) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"boot rebuild": func() (cli.Command, error) {
+			return &BootRebuildCommand{
+				BootCommand: BootCommand{
+					KernelCommand: KernelCommand{
+						PkgCommand: PkgCommand{
+							CoreCommand: CoreCommand{
+								Debug:        debug,
+								HelpText:     "void boot rebuild [--kernel VER|--all]",
+								SynopsisText: "Regenerate initramfs images and bootloader configuration",
+								Flags: c.flags(
+									CoreFlag{
+										Name:        "kernel",
+										Description: "Kernel version to rebuild (defaults to the running kernel)"},
+									CoreFlag{
+										Name:        "all",
+										Boolean:     true,
+										Description: "Rebuild all kernels in /boot"},
+									CoreFlag{
+										Name:        "reconfigure",
+										Boolean:     true,
+										Description: "Reconfigure the kernel packages to run all kernel hooks instead of dracut"},
+									CoreFlag{
+										Name:        "no-bootloader",
+										Boolean:     true,
+										Description: "Do not regenerate the bootloader configuration"},
+									CoreFlag{
+										Name:        "check",
+										Boolean:     true,
+										Description: "Only report missing and stale images"},
+									CoreFlag{
+										Name:        "json",
+										Boolean:     true,
+										Description: "Output check results as JSON"}),
+								UI:      ui,
+								AppName: appName,
+							},
+						},
+					},
+				},
+			}, nil
+		},
+		"boot services disable": func() (cli.Command, error) {
+			return &BootServicesDisableCommand{
+				BootCommand: BootCommand{
+					KernelCommand: KernelCommand{
+						PkgCommand: PkgCommand{
+							CoreCommand: CoreCommand{
+								Debug:        debug,
+								HelpText:     "void boot services disable NAME",
+								SynopsisText: "Disable a core service run at boot",
+								Flags: c.flags(
+									CoreFlag{
+										Name:        "restore",
+										Boolean:     true,
+										Description: "Restore a disabled core service"}),
+								UI:      ui,
+								AppName: appName,
+							},
+						},
+					},
+				},
+			}, nil
+		},
+		"boot services list": func() (cli.Command, error) {
+			return &BootServicesListCommand{
+				BootCommand: BootCommand{
+					KernelCommand: KernelCommand{
+						PkgCommand: PkgCommand{
+							CoreCommand: CoreCommand{
+								Debug:        debug,
+								HelpText:     "void boot services list",
+								SynopsisText: "List core services in boot order",
+								Flags: c.flags(
+									CoreFlag{
+										Name:        "json",
+										Boolean:     true,
+										Description: "Output as JSON"}),
+								UI:      ui,
+								AppName: appName,
+							},
+						},
+					},
+				},
+			}, nil
+		},
+		"boot services show": func() (cli.Command, error) {
+			return &BootServicesShowCommand{
+				BootCommand: BootCommand{
+					KernelCommand: KernelCommand{
+						PkgCommand: PkgCommand{
+							CoreCommand: CoreCommand{
+								Debug:        debug,
+								HelpText:     "void boot services show NAME",
+								SynopsisText: "Show what a core service does",
+								Flags: c.flags(
+									CoreFlag{
+										Name:        "source",
+										Boolean:     true,
+										Description: "Display the script source"},
+									CoreFlag{
+										Name:        "json",
+										Boolean:     true,
+										Description: "Output as JSON"}),
+								UI:      ui,
+								AppName: appName,
+							},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+}
+
+// Checks the initramfs of every kernel image in /boot. Images are
+// stale when older than their kernel, its module tree or the dracut
+// configuration.
+func (c *BootCommand) BootImages() ([]*BootImage, error) {
+	images, err := filepath.Glob(filepath.Join(c.RootDir, KERNEL_BOOT_PATH, "vmlinuz-*"))
+	if err != nil {
+		return nil, err
+	}
+	configs := []string{DRACUT_CONFIG_PATH}
+	for _, dir := range []string{DRACUT_CONFIG_DIR_PATH, DRACUT_LIB_CONFIG_DIR_PATH} {
+		configs = append(configs, c.globRoot(filepath.Join(dir, "*.conf"))...)
+	}
+	configTime := c.newestMtime(configs...)
+	result := []*BootImage{}
+	for _, image := range images {
+		version := filepath.Base(image)[len("vmlinuz-"):]
+		boot := &BootImage{
+			Version:   version,
+			Kernel:    filepath.Join(KERNEL_BOOT_PATH, "vmlinuz-"+version),
+			Initramfs: filepath.Join(KERNEL_BOOT_PATH, fmt.Sprintf("initramfs-%s.img", version)),
+			Status:    "ok"}
+		result = append(result, boot)
+		info, err := os.Stat(filepath.Join(c.RootDir, boot.Initramfs))
+		if err != nil {
+			boot.Status = "missing"
+			continue
+		}
+		if info.Size() == 0 {
+			boot.Status, boot.Reason = "stale", "initramfs is empty"
+			continue
+		}
+		built := info.ModTime()
+		modules := filepath.Join(KERNEL_MODULES_PATH, version, "modules.dep")
+		switch {
+		case c.newestMtime(boot.Kernel).After(built):
+			boot.Status, boot.Reason = "stale", "kernel image is newer"
+		case c.newestMtime(modules).After(built):
+			boot.Status, boot.Reason = "stale", "kernel modules are newer"
+		case configTime.After(built):
+			boot.Status, boot.Reason = "stale", "dracut configuration is newer"
+		}
+		if _, err := os.Stat(filepath.Join(c.RootDir, KERNEL_MODULES_PATH, version)); err != nil {
+			boot.Status, boot.Reason = "stale", "kernel modules are missing"
+		}
+	}
+	return result, nil
+}
+
+func (c *BootCommand) globRoot(pattern string) []string {
+	paths := []string{}
+	matches, _ := filepath.Glob(filepath.Join(c.RootDir, pattern))
+	for _, match := range matches {
+		rel, _ := filepath.Rel(c.RootDir, match)
+		paths = append(paths, "/"+rel)
+	}
+	return paths
+}
+
+// Latest modification time of the given paths below the root
+func (c *BootCommand) newestMtime(paths ...string) time.Time {
+	newest := time.Time{}
+	for _, path := range paths {
+		if info, err := os.Stat(filepath.Join(c.RootDir, path)); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest
+}
@@ -0,0 +1,166 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type BootRebuildCommand struct {
+	BootCommand
+}
+
+func (c *BootRebuildCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup boot command: %s", err))
+		return exitCode
+	}
+	images, err := c.BootImages()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to inspect boot images: %s", err))
+		return exitCode
+	}
+	if cOpts.Get("check") != nil {
+		return c.check(images, cOpts.Get("json") != nil)
+	}
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	if c.RootDir != "/" {
+		c.UI.Error("Images can only be rebuilt for the running system, use `--check` with `--rootdir`!")
+		return exitCode
+	}
+	versions := []string{}
+	flag := cOpts.Get("kernel")
+	switch {
+	case flag != nil && cOpts.Get("all") != nil:
+		c.UI.Error("Only one of `--kernel` or `--all` may be used!")
+		return exitCode
+	case cOpts.Get("all") != nil:
+		for _, image := range images {
+			versions = append(versions, image.Version)
+		}
+	case flag != nil:
+		version := strings.TrimSpace(flag.Value)
+		if version == "" || strings.Contains(version, "/") {
+			c.UI.Error(fmt.Sprintf(
+				"Invalid kernel version `%s`!", flag.Value))
+			return exitCode
+		}
+		versions = append(versions, version)
+	default:
+		running := c.RunningKernel()
+		if running == "" {
+			c.UI.Error("Failed to determine the running kernel, use `--kernel VER`!")
+			return exitCode
+		}
+		versions = append(versions, running)
+	}
+	var db *PkgDB
+	reconfigured := false
+	if cOpts.Get("reconfigure") != nil {
+		if db, err = c.PkgDB(); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to load package database: %s", err))
+			return exitCode
+		}
+	}
+	for _, version := range versions {
+		if info, err := os.Stat(filepath.Join(KERNEL_MODULES_PATH, version)); err != nil || !info.IsDir() {
+			if cOpts.Get("all") != nil {
+				c.UI.Warn(fmt.Sprintf(
+					"Skipping kernel `%s` without module tree", version))
+				continue
+			}
+			c.UI.Error(fmt.Sprintf(
+				"Kernel `%s` has no module tree in %s!", version, KERNEL_MODULES_PATH))
+			return exitCode
+		}
+		initramfs := filepath.Join(KERNEL_BOOT_PATH, fmt.Sprintf("initramfs-%s.img", version))
+		if db != nil {
+			// The kernel package hooks run dracut and update the
+			// bootloader configuration themselves
+			pkgName := "linux" + kernelSeries(version)
+			if pkg, ok := db.Packages[pkgName]; ok && pkg.Version == version {
+				c.UI.Output(fmt.Sprintf("Reconfiguring %s", pkg.PkgVer))
+				if c.ExecuteCommand(exec.Command(XBPS_RECONFIGURE_PATH, "-f", pkgName)) != 0 {
+					c.UI.Error(fmt.Sprintf(
+						"Failed to reconfigure package `%s`!", pkgName))
+					return exitCode
+				}
+				reconfigured = true
+				c.UI.Info(fmt.Sprintf("Rebuilt initramfs: %s", initramfs))
+				continue
+			}
+			c.UI.Warn(fmt.Sprintf(
+				"Kernel `%s` is not provided by an installed package, skipping reconfigure", version))
+		}
+		c.UI.Output(fmt.Sprintf("Generating initramfs for %s", version))
+		if c.ExecuteCommand(exec.Command(DRACUT_PATH, "--force", initramfs, version)) != 0 {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate initramfs `%s`!", initramfs))
+			return exitCode
+		}
+		c.UI.Info(fmt.Sprintf("Rebuilt initramfs: %s", initramfs))
+	}
+	if reconfigured {
+		c.debug("Bootloader configuration updated by the kernel hooks")
+	} else if cOpts.Get("no-bootloader") == nil {
+		if _, err := os.Stat(filepath.Dir(GRUB_CONFIG_PATH)); err != nil {
+			c.debug("No grub configuration directory found, skipping bootloader")
+		} else {
+			c.UI.Output("Generating bootloader configuration")
+			if c.ExecuteCommand(exec.Command(GRUB_MKCONFIG_PATH, "-o", GRUB_CONFIG_PATH)) != 0 {
+				c.UI.Error("Failed to generate bootloader configuration!")
+				return exitCode
+			}
+			c.UI.Info(fmt.Sprintf("Updated bootloader configuration: %s", GRUB_CONFIG_PATH))
+		}
+	}
+	if images, err = c.BootImages(); err == nil {
+		for _, image := range images {
+			if image.Status != "ok" {
+				c.UI.Warn(fmt.Sprintf("Initramfs of %s is %s", image.Version, image.Status))
+			}
+		}
+	}
+	return 0
+}
+
+func (c *BootRebuildCommand) check(images []*BootImage, asJSON bool) int {
+	problems := 0
+	for _, image := range images {
+		if image.Status != "ok" {
+			problems++
+		}
+	}
+	if asJSON {
+		if err := c.outputJSON(images); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return 1
+		}
+	} else {
+		rows := [][]string{}
+		for _, image := range images {
+			rows = append(rows, []string{image.Version, image.Initramfs, image.Status, image.Reason})
+		}
+		c.outputTable([]string{"VERSION", "INITRAMFS", "STATUS", "REASON"}, rows)
+		if problems > 0 {
+			c.UI.Output("")
+			c.UI.Warn(fmt.Sprintf(
+				"%d kernels have missing or stale images (run `%s boot rebuild --all`)", problems, c.AppName))
+		}
+	}
+	if problems > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"fmt"
+	"os"
+)
+
+type BootServicesDisableCommand struct {
+	BootCommand
+}
+
+func (c *BootServicesDisableCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup boot command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single core service name required!")
+		return exitCode
+	}
+	service, err := c.CoreService(cOpts.Args[0])
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.UI.Error(fmt.Sprintf(
+				"Core service `%s` does not exist!", cOpts.Args[0]))
+		} else {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to load core service: %s", err))
+		}
+		return exitCode
+	}
+	if cOpts.Get("restore") != nil {
+		if !service.Disabled {
+			c.UI.Error(fmt.Sprintf(
+				"Core service `%s` is not disabled!", service.Name))
+			return exitCode
+		}
+		if err := c.RestoreCoreService(service); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to restore core service: %s", err))
+			return exitCode
+		}
+		c.UI.Info(fmt.Sprintf("Restored core service: %s", service.Name))
+		return 0
+	}
+	if service.Disabled {
+		c.UI.Error(fmt.Sprintf(
+			"Core service `%s` is already disabled!", service.Name))
+		return exitCode
+	}
+	if err := c.DisableCoreService(service); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to disable core service: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf("Disabled core service: %s", service.Name))
+	if service.System == "" {
+		c.UI.Warn("Core service has no system copy, package updates may restore it")
+	}
+	return 0
+}
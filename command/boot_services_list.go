@@ -0,0 +1,53 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type BootServicesListCommand struct {
+	BootCommand
+}
+
+func (c *BootServicesListCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup boot command: %s", err))
+		return exitCode
+	}
+	services, err := c.CoreServices()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to list core services: %s", err))
+		return exitCode
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(services); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	rows := [][]string{}
+	for _, service := range services {
+		status := "enabled"
+		if service.Disabled {
+			status = "disabled"
+		}
+		if service.Overridden {
+			status = status + " (override)"
+		}
+		summary := ""
+		if len(service.Messages) > 0 {
+			summary = service.Messages[0]
+		} else if len(service.Description) > 0 {
+			summary = service.Description[0]
+		}
+		rows = append(rows, []string{strconv.Itoa(service.Order), service.Name, status, summary})
+	}
+	c.outputTable([]string{"ORDER", "NAME", "STATUS", "SUMMARY"}, rows)
+	return 0
+}
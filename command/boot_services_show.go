@@ -0,0 +1,81 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type BootServicesShowCommand struct {
+	BootCommand
+}
+
+func (c *BootServicesShowCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup boot command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single core service name required!")
+		return exitCode
+	}
+	service, err := c.CoreService(cOpts.Args[0])
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.UI.Error(fmt.Sprintf(
+				"Core service `%s` does not exist!", cOpts.Args[0]))
+		} else {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to load core service: %s", err))
+		}
+		return exitCode
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(service); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	status := "enabled"
+	if service.Disabled {
+		status = "disabled"
+	}
+	rows := [][]string{
+		[]string{"Name:", service.Name},
+		[]string{"Order:", fmt.Sprintf("%d", service.Order)},
+		[]string{"Path:", service.Path},
+		[]string{"Status:", status}}
+	if service.Overridden {
+		rows = append(rows, []string{"Overrides:", service.System})
+	}
+	c.outputTable(nil, rows)
+	if len(service.Description) > 0 {
+		c.UI.Output("")
+		c.UI.Output(strings.Join(service.Description, "\n"))
+	}
+	if len(service.Messages) > 0 {
+		c.UI.Output("")
+		c.UI.Output("Steps:")
+		for _, message := range service.Messages {
+			c.UI.Output("  - " + message)
+		}
+	}
+	if cOpts.Get("source") != nil {
+		content, err := ioutil.ReadFile(filepath.Join(c.RootDir, service.Path))
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to read core service: %s", err))
+			return exitCode
+		}
+		c.UI.Output("")
+		c.UI.Output(strings.TrimRight(string(content), "\n"))
+	}
+	return 0
+}
@@ -1,13 +1,18 @@
 package command
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/mitchellh/cli"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 )
 
 // Base command stubs
@@ -41,6 +46,27 @@ func (p *ParsedCli) Get(name string) *CoreFlag {
 	return nil
 }
 
+// Root directory of the system to act on. Commands that predate
+// `--rootdir` also register `--root`, which is checked first since it
+// has no default value.
+func (p *ParsedCli) RootDir() string {
+	for _, name := range []string{"root", "rootdir"} {
+		if flag := p.Get(name); flag != nil && flag.Value != "" {
+			return flag.Value
+		}
+	}
+	return "/"
+}
+
+// Flag selecting the root directory of the system to act on
+func rootDirFlag() CoreFlag {
+	return CoreFlag{
+		Name:        "rootdir",
+		Boolean:     false,
+		Description: "Root directory of the system",
+		Default:     "/"}
+}
+
 func (c *CoreCommand) Flag(name string) (CoreFlag, error) {
 	var flag CoreFlag
 	for _, flag := range c.Flags {
@@ -83,6 +109,30 @@ func Commands(appName string, ui cli.Ui, debug bool) map[string]cli.CommandFacto
 	for k, v := range (&GopherCommand{}).Commands(appName, ui, debug) {
 		cmds[k] = v
 	}
+	for k, v := range (&PkgCommand{}).Commands(appName, ui, debug) {
+		cmds[k] = v
+	}
+	for k, v := range (&RepoCommand{}).Commands(appName, ui, debug) {
+		cmds[k] = v
+	}
+	for k, v := range (&AlternativesCommand{}).Commands(appName, ui, debug) {
+		cmds[k] = v
+	}
+	for k, v := range (&KernelCommand{}).Commands(appName, ui, debug) {
+		cmds[k] = v
+	}
+	for k, v := range (&BootCommand{}).Commands(appName, ui, debug) {
+		cmds[k] = v
+	}
+	for k, v := range (&SystemCommand{}).Commands(appName, ui, debug) {
+		cmds[k] = v
+	}
+	for k, v := range (&LogCommand{}).Commands(appName, ui, debug) {
+		cmds[k] = v
+	}
+	for k, v := range (&ScheduleCommand{}).Commands(appName, ui, debug) {
+		cmds[k] = v
+	}
 	return cmds
 }
 
@@ -119,6 +169,7 @@ func (c *CoreCommand) Parse(args []string) (ParsedCli, error) {
 			parsed.Flags[flag.Name] = flag
 		} else {
 			lastItem.Value = argItem
+			parsed.Flags[lastItem.Name] = lastItem
 			setLast = false
 		}
 	}
@@ -182,6 +233,16 @@ func (c *CoreCommand) debug(line string) {
 	}
 }
 
+// Asks for confirmation, accepting `y` or `yes`
+func (c *CoreCommand) confirm(prompt string) (bool, error) {
+	answer, err := c.UI.Ask(prompt + " [y/N]")
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
 func (c *CoreCommand) contains(haystack []string, needle string) bool {
 	for _, v := range haystack {
 		if v == needle {
@@ -190,3 +251,55 @@ func (c *CoreCommand) contains(haystack []string, needle string) bool {
 	}
 	return false
 }
+
+// Writes the given value to the UI as indented JSON
+func (c *CoreCommand) outputJSON(v interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	c.UI.Output(strings.TrimRight(buf.String(), "\n"))
+	return nil
+}
+
+// Writes the given rows to the UI as aligned columns
+func (c *CoreCommand) outputTable(header []string, rows [][]string) {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	if len(header) > 0 {
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	c.UI.Output(strings.TrimRight(buf.String(), "\n"))
+}
+
+// Writes content to a temporary file next to the destination and
+// renames it into place so readers never observe a partial file.
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,147 @@
+package command
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Core service scripts are sourced by stage 1 in lexical order. Scripts
+// in the configuration directory override same-named system scripts.
+const CORE_SERVICES_PATH = "/etc/runit/core-services"
+const CORE_SERVICES_SYSTEM_PATH = "/usr/lib/runit/core-services"
+const CORE_SERVICE_DISABLED_MARKER = "# Disabled by void boot services disable"
+
+var coreServiceMsg = regexp.MustCompile(`^\s*msg(_warn)?\s+["'](.*?)\.*["']`)
+
+// Core service script as resolved for stage 1
+type CoreService struct {
+	Order       int      `json:"order"`
+	Name        string   `json:"name"`
+	Path        string   `json:"path"`
+	System      string   `json:"system,omitempty"`
+	Overridden  bool     `json:"overridden"`
+	Disabled    bool     `json:"disabled"`
+	Description []string `json:"description,omitempty"`
+	Messages    []string `json:"messages,omitempty"`
+}
+
+// Resolves the core service scripts in execution order
+func (c *BootCommand) CoreServices() ([]*CoreService, error) {
+	scripts := map[string]*CoreService{}
+	for _, dir := range []string{CORE_SERVICES_SYSTEM_PATH, CORE_SERVICES_PATH} {
+		paths, err := filepath.Glob(filepath.Join(c.RootDir, dir, "*.sh"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			name := filepath.Base(path)
+			logical := filepath.Join(dir, name)
+			if existing, ok := scripts[name]; ok {
+				existing.Path = logical
+				existing.Overridden = true
+				continue
+			}
+			scripts[name] = &CoreService{Name: name, Path: logical}
+			if dir == CORE_SERVICES_SYSTEM_PATH {
+				scripts[name].System = logical
+			}
+		}
+	}
+	names := []string{}
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	services := []*CoreService{}
+	for idx, name := range names {
+		service := scripts[name]
+		service.Order = idx + 1
+		if err := c.describeCoreService(service); err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+func (c *BootCommand) CoreService(name string) (*CoreService, error) {
+	services, err := c.CoreServices()
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services {
+		if service.Name == name || service.Name == name+".sh" {
+			return service, nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// Collects the leading comment block and the progress messages a
+// script prints. Scripts without any commands are disabled.
+func (c *BootCommand) describeCoreService(service *CoreService) error {
+	file, err := os.Open(filepath.Join(c.RootDir, service.Path))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	service.Disabled = true
+	header := true
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#!") || strings.HasPrefix(line, "# vim"):
+			continue
+		case strings.HasPrefix(line, "#"):
+			if header && line != CORE_SERVICE_DISABLED_MARKER {
+				service.Description = append(service.Description, strings.TrimSpace(strings.TrimLeft(line, "#")))
+			}
+			continue
+		}
+		header = false
+		service.Disabled = false
+		if match := coreServiceMsg.FindStringSubmatch(line); match != nil {
+			service.Messages = append(service.Messages, match[2])
+		}
+	}
+	return scanner.Err()
+}
+
+// Disables a core service with an empty override in the configuration
+// directory, which keeps working across package updates
+func (c *BootCommand) DisableCoreService(service *CoreService) error {
+	dir := filepath.Join(c.RootDir, CORE_SERVICES_PATH)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, service.Name)
+	if service.System == "" || service.Overridden {
+		// Keep local scripts around so the service can be restored
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(path+".orig", content, 0644); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(path, []byte(CORE_SERVICE_DISABLED_MARKER+"\n"), 0644)
+}
+
+// Removes a disabling override, restoring the original script
+func (c *BootCommand) RestoreCoreService(service *CoreService) error {
+	path := filepath.Join(c.RootDir, CORE_SERVICES_PATH, service.Name)
+	if _, err := os.Stat(path + ".orig"); err == nil {
+		return os.Rename(path+".orig", path)
+	}
+	if service.System == "" {
+		return os.ErrNotExist
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DIFF_CONTEXT = 3
+
+// Largest LCS table built for a diff, larger changes are summarized
+const DIFF_MAX_CELLS = 4000000
+
+// Edit script entry as (op, old index, new index)
+type diffEdit struct {
+	op   byte
+	i, j int
+}
+
+// Produces a unified diff between two texts. Returns an empty string
+// when both texts are equal. Common leading and trailing lines are
+// skipped and the remaining lines are compared using a plain LCS
+// table, which is fine for the configuration files this is used on.
+// Changes too large for the table are reported as a summary.
+func unifiedDiff(oldName string, newName string, oldText string, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	a := splitLines(oldText)
+	b := splitLines(newText)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	changedA, changedB := len(a)-prefix-suffix, len(b)-prefix-suffix
+	if (changedA+1)*(changedB+1) > DIFF_MAX_CELLS {
+		return fmt.Sprintf("--- %s\n+++ %s\nFiles differ in %d lines (%d lines changed to %d, too large to diff)",
+			oldName, newName, changedA+changedB, changedA, changedB)
+	}
+	edits := []diffEdit{}
+	for k := 0; k < prefix; k++ {
+		edits = append(edits, diffEdit{' ', k, k})
+	}
+	edits = append(edits, lcsEdits(a, b, prefix, len(a)-suffix, len(b)-suffix)...)
+	for k := suffix; k > 0; k-- {
+		edits = append(edits, diffEdit{' ', len(a) - k, len(b) - k})
+	}
+	out := []string{"--- " + oldName, "+++ " + newName}
+	for start := 0; start < len(edits); {
+		// Find the next change and the extent of its hunk
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		first := start - DIFF_CONTEXT
+		if first < 0 {
+			first = 0
+		}
+		last := start
+		for k := start; k < len(edits); k++ {
+			if edits[k].op != ' ' {
+				last = k
+			} else if k-last > 2*DIFF_CONTEXT {
+				break
+			}
+		}
+		end := last + DIFF_CONTEXT + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+		oldCount, newCount := 0, 0
+		lines := []string{}
+		for _, e := range edits[first:end] {
+			switch e.op {
+			case ' ':
+				oldCount++
+				newCount++
+				lines = append(lines, " "+a[e.i])
+			case '-':
+				oldCount++
+				lines = append(lines, "-"+a[e.i])
+			case '+':
+				newCount++
+				lines = append(lines, "+"+b[e.j])
+			}
+		}
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@",
+			hunkRange(edits[first].i, oldCount), hunkRange(edits[first].j, newCount)))
+		out = append(out, lines...)
+		start = end
+	}
+	return strings.Join(out, "\n")
+}
+
+// Edit script between a[start:endA] and b[start:endB]
+func lcsEdits(a []string, b []string, start int, endA int, endB int) []diffEdit {
+	n, m := endA-start, endB-start
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[start+i] == b[start+j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	edits := []diffEdit{}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[start+i] == b[start+j]:
+			edits = append(edits, diffEdit{' ', start + i, start + j})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			edits = append(edits, diffEdit{'+', start + i, start + j})
+			j++
+		default:
+			edits = append(edits, diffEdit{'-', start + i, start + j})
+			i++
+		}
+	}
+	return edits
+}
+
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
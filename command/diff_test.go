@@ -0,0 +1,42 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	cases := []struct {
+		name     string
+		oldText  string
+		newText  string
+		expected string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", ""},
+		{"added only", "", "a\nb\n",
+			"--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b"},
+		{"removed only", "a\nb\n", "",
+			"--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b"},
+		{"appended", "a\nb\n", "a\nb\nc\n",
+			"--- old\n+++ new\n@@ -1,2 +1,3 @@\n a\n b\n+c"},
+		{"changed", "1\n2\n3\n4\n5\n6\n7\n8\n9\n", "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			"--- old\n+++ new\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8"},
+	}
+	for _, tc := range cases {
+		if diff := unifiedDiff("old", "new", tc.oldText, tc.newText); diff != tc.expected {
+			t.Errorf("%s: unifiedDiff() =\n%s\nexpected\n%s", tc.name, diff, tc.expected)
+		}
+	}
+}
+
+func TestUnifiedDiffTooLarge(t *testing.T) {
+	oldLines, newLines := []string{"same"}, []string{"same"}
+	for i := 0; i < 3000; i++ {
+		oldLines = append(oldLines, "old")
+		newLines = append(newLines, "new")
+	}
+	diff := unifiedDiff("old", "new", strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"))
+	if !strings.Contains(diff, "too large to diff") || strings.Contains(diff, "@@") {
+		t.Errorf("unifiedDiff() of large change = %s", diff)
+	}
+}
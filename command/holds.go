@@ -0,0 +1,71 @@
+package command
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+const VOID_HOLDS_PATH = "/var/db/xbps/void-holds.json"
+const XBPS_PKGDB_PATH = "/usr/bin/xbps-pkgdb"
+
+// Note recorded when a package is held, so it is known why a
+// package is frozen and at which version. The hold and repolock
+// modes themselves are only tracked in the pkgdb.
+type HoldNote struct {
+	Version string `json:"version"`
+	Reason  string `json:"reason,omitempty"`
+	Date    string `json:"date"`
+	User    string `json:"user,omitempty"`
+}
+
+// Hold notes keyed by package name
+type HoldNotes map[string]HoldNote
+
+func holdNotesPath(rootDir string) string {
+	return filepath.Join(rootDir, VOID_HOLDS_PATH)
+}
+
+// Loads the hold notes below the given root directory. A missing
+// notes file is not an error.
+func LoadHoldNotes(rootDir string) (HoldNotes, error) {
+	notes := HoldNotes{}
+	content, err := ioutil.ReadFile(holdNotesPath(rootDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return notes, nil
+		}
+		return notes, err
+	}
+	err = json.Unmarshal(content, &notes)
+	return notes, err
+}
+
+// Sorted names of packages with hold notes
+func (h HoldNotes) Names() []string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Writes the hold notes, replacing the existing file atomically
+func (h HoldNotes) Save(rootDir string) error {
+	content, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(holdNotesPath(rootDir), append(content, '\n'), 0644)
+}
+
+// Sets a package mode (hold, unhold, repolock, repounlock, auto,
+// manual) in the pkgdb using xbps-pkgdb
+func (c *PkgCommand) SetPkgMode(name string, mode string) bool {
+	xArgs := append(c.xbpsRootArgs(), "-m", mode, name)
+	return c.ExecuteCommand(exec.Command(XBPS_PKGDB_PATH, xArgs...)) == 0
+}
@@ -0,0 +1,331 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/cli"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const KERNEL_MODULES_PATH = "/lib/modules"
+const KERNEL_BOOT_PATH = "/boot"
+const KERNEL_HOOKS_PATH = "/etc/kernel.d"
+const KERNEL_RELEASE_PATH = "/proc/sys/kernel/osrelease"
+const VOID_KERNEL_PINS_PATH = "/var/db/xbps/void-kernel-pins.json"
+
+var kernelPkgPattern = regexp.MustCompile(`^linux[0-9]+\.[0-9]+$`)
+
+// Files installed to /boot for each kernel version
+var kernelBootFiles = []string{"vmlinuz-%s", "initramfs-%s.img", "config-%s", "System.map-%s"}
+
+// Kernel command stub
+type KernelCommand struct {
+	PkgCommand
+	Version string
+}
+
+// Kernel version found installed as a package, module tree or in /boot
+type Kernel struct {
+	Version    string   `json:"version"`
+	Series     string   `json:"series"`
+	Package    string   `json:"package,omitempty"`
+	PkgVer     string   `json:"pkgver,omitempty"`
+	Modules    bool     `json:"modules"`
+	BootFiles  []string `json:"boot_files"`
+	Running    bool     `json:"running"`
+	Pinned     bool     `json:"pinned"`
+	Orphaned   bool     `json:"orphaned"`
+	RequiredBy []string `json:"required_by,omitempty"`
+}
+
+func (c *KernelCommand) Commands(appName string, ui cli.Ui, debug bool) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"kernel current": func() (cli.Command, error) {
+			return &KernelCurrentCommand{
+				KernelCommand: KernelCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void kernel current",
+							SynopsisText: "Display the running kernel",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "json",
+									Boolean:     true,
+									Description: "Output as JSON"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"kernel list": func() (cli.Command, error) {
+			return &KernelListCommand{
+				KernelCommand: KernelCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void kernel list",
+							SynopsisText: "List installed kernels",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "json",
+									Boolean:     true,
+									Description: "Output as JSON"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"kernel pin": func() (cli.Command, error) {
+			return &KernelPinCommand{
+				KernelCommand: KernelCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void kernel pin VERSION",
+							SynopsisText: "Protect a kernel from being purged",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "remove",
+									Boolean:     true,
+									Description: "Remove the pin"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"kernel purge": func() (cli.Command, error) {
+			return &KernelPurgeCommand{
+				KernelCommand: KernelCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void kernel purge [VERSION...]",
+							SynopsisText: "Remove old kernels",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "yes",
+									Boolean:     true,
+									Description: "Purge without confirmation"},
+								CoreFlag{
+									Name:        "dry-run",
+									Boolean:     true,
+									Description: "Only show what would be removed"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+	}
+}
+
+func (c *KernelCommand) Init(args []string, version bool) (ParsedCli, error) {
+	fmtOpts, err := c.PkgCommand.Init(args, false)
+	if err != nil {
+		return fmtOpts, err
+	}
+	if version {
+		if len(fmtOpts.Args) != 1 {
+			return fmtOpts, errors.New("Single kernel version required!")
+		} else {
+			c.Version = fmtOpts.Args[0]
+		}
+	}
+	return fmtOpts, nil
+}
+
+// Release of the running kernel. Only meaningful for the live root.
+func (c *KernelCommand) RunningKernel() string {
+	content, err := ioutil.ReadFile(KERNEL_RELEASE_PATH)
+	if err != nil {
+		c.debug(fmt.Sprintf(
+			"Failed to read running kernel release: %s", err))
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// Collects kernels from linuxX.Y packages, module trees and /boot.
+// Versions without an installed package are orphaned leftovers of
+// previous kernel upgrades.
+func (c *KernelCommand) Kernels(db *PkgDB) ([]*Kernel, error) {
+	pins, err := LoadKernelPins(c.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	kernels := map[string]*Kernel{}
+	kernel := func(version string) *Kernel {
+		if _, ok := kernels[version]; !ok {
+			kernels[version] = &Kernel{
+				Version:   version,
+				Series:    kernelSeries(version),
+				BootFiles: []string{}}
+		}
+		return kernels[version]
+	}
+	revDeps := db.ReverseDepends()
+	for _, name := range db.Names() {
+		if !kernelPkgPattern.MatchString(name) {
+			continue
+		}
+		pkg := db.Packages[name]
+		k := kernel(pkg.Version)
+		k.Package = name
+		k.PkgVer = pkg.PkgVer
+		k.RequiredBy = revDeps[name]
+	}
+	modules, err := ioutil.ReadDir(filepath.Join(c.RootDir, KERNEL_MODULES_PATH))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, info := range modules {
+		if info.IsDir() {
+			kernel(info.Name()).Modules = true
+		}
+	}
+	images, _ := filepath.Glob(filepath.Join(c.RootDir, KERNEL_BOOT_PATH, "vmlinuz-*"))
+	for _, image := range images {
+		kernel(strings.TrimPrefix(filepath.Base(image), "vmlinuz-"))
+	}
+	running := ""
+	if c.RootDir == "/" {
+		running = c.RunningKernel()
+	}
+	result := []*Kernel{}
+	for _, k := range kernels {
+		for _, pattern := range kernelBootFiles {
+			path := filepath.Join(KERNEL_BOOT_PATH, fmt.Sprintf(pattern, k.Version))
+			if _, err := os.Stat(filepath.Join(c.RootDir, path)); err == nil {
+				k.BootFiles = append(k.BootFiles, path)
+			}
+		}
+		k.Running = k.Version == running
+		k.Pinned = c.contains(pins, k.Version)
+		k.Orphaned = k.Package == ""
+		result = append(result, k)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return compareVersions(result[i].Version, result[j].Version) < 0
+	})
+	return result, nil
+}
+
+// Checks if a kernel may be purged, returning the reason if not
+func (c *KernelCommand) Protected(k *Kernel) string {
+	switch {
+	case k.Running:
+		return "running kernel"
+	case k.Pinned:
+		return "pinned"
+	case len(k.RequiredBy) > 0:
+		return "required by " + strings.Join(k.RequiredBy, ",")
+	}
+	return ""
+}
+
+// Removes the files of an orphaned kernel and runs the post-remove
+// kernel hooks, matching what vkpurge does
+func (c *KernelCommand) RemoveKernelFiles(k *Kernel) error {
+	if c.RootDir == "/" {
+		c.runKernelHooks("post-remove", k)
+	} else {
+		c.debug(fmt.Sprintf(
+			"Skipping kernel hooks for alternate root `%s`", c.RootDir))
+	}
+	for _, path := range k.BootFiles {
+		if err := os.Remove(filepath.Join(c.RootDir, path)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if k.Modules {
+		return os.RemoveAll(filepath.Join(c.RootDir, KERNEL_MODULES_PATH, k.Version))
+	}
+	return nil
+}
+
+func (c *KernelCommand) runKernelHooks(stage string, k *Kernel) {
+	hooks, _ := filepath.Glob(filepath.Join(KERNEL_HOOKS_PATH, stage, "*"))
+	sort.Strings(hooks)
+	for _, hook := range hooks {
+		if info, err := os.Stat(hook); err != nil || info.Mode()&0111 == 0 {
+			continue
+		}
+		if c.ExecuteCommand(exec.Command(hook, "linux"+k.Series, k.Version)) != 0 {
+			c.UI.Warn(fmt.Sprintf(
+				"Kernel hook `%s` failed for %s", hook, k.Version))
+		}
+	}
+}
+
+// Major and minor version of a kernel release (`6.1.55_1` -> `6.1`)
+func kernelSeries(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	minor := parts[1]
+	if idx := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); idx != -1 {
+		minor = minor[:idx]
+	}
+	return parts[0] + "." + minor
+}
+
+func kernelPinsPath(rootDir string) string {
+	return filepath.Join(rootDir, VOID_KERNEL_PINS_PATH)
+}
+
+// Loads the pinned kernel versions. A missing pins file is not an error.
+func LoadKernelPins(rootDir string) ([]string, error) {
+	pins := []string{}
+	content, err := ioutil.ReadFile(kernelPinsPath(rootDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pins, nil
+		}
+		return pins, err
+	}
+	err = json.Unmarshal(content, &pins)
+	return pins, err
+}
+
+func SaveKernelPins(rootDir string, pins []string) error {
+	sort.Strings(pins)
+	content, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(kernelPinsPath(rootDir), append(content, '\n'), 0644)
+}
+
+// Kernel statuses for display
+func kernelStatus(k *Kernel) string {
+	status := []string{}
+	if k.Running {
+		status = append(status, "running")
+	}
+	if k.Pinned {
+		status = append(status, "pinned")
+	}
+	if k.Orphaned {
+		status = append(status, "orphaned")
+	}
+	if !k.Modules {
+		status = append(status, "no-modules")
+	}
+	return strings.Join(status, ",")
+}
@@ -0,0 +1,65 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type KernelCurrentCommand struct {
+	KernelCommand
+}
+
+func (c *KernelCurrentCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup kernel command: %s", err))
+		return exitCode
+	}
+	running := c.RunningKernel()
+	if running == "" {
+		c.UI.Error("Failed to determine the running kernel!")
+		return exitCode
+	}
+	current := &Kernel{Version: running, Series: kernelSeries(running), Running: true, BootFiles: []string{}}
+	if _, err := os.Stat(filepath.Join(c.RootDir, KERNEL_MODULES_PATH, running)); err == nil {
+		current.Modules = true
+	}
+	// Package information is optional for displaying the release
+	if db, err := c.PkgDB(); err != nil {
+		c.debug(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+	} else {
+		kernels, err := c.Kernels(db)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to list kernels: %s", err))
+			return exitCode
+		}
+		for _, k := range kernels {
+			if k.Version == running {
+				current = k
+			}
+		}
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(current); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	c.UI.Output(current.Version)
+	if current.Package == "" {
+		c.UI.Warn("Running kernel is not provided by an installed package")
+	} else {
+		c.UI.Output(fmt.Sprintf("Package: %s", current.PkgVer))
+	}
+	if !current.Modules {
+		c.UI.Warn("Module tree of the running kernel is missing (reboot recommended)")
+	}
+	return 0
+}
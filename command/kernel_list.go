@@ -0,0 +1,58 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type KernelListCommand struct {
+	KernelCommand
+}
+
+func (c *KernelListCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup kernel command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	kernels, err := c.Kernels(db)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to list kernels: %s", err))
+		return exitCode
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(kernels); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	rows := [][]string{}
+	for _, k := range kernels {
+		mark := ""
+		if k.Running {
+			mark = "*"
+		}
+		pkg := k.Package
+		if pkg == "" {
+			pkg = "-"
+		}
+		boot := []string{}
+		for _, path := range k.BootFiles {
+			boot = append(boot, strings.SplitN(strings.TrimPrefix(path, KERNEL_BOOT_PATH+"/"), "-", 2)[0])
+		}
+		rows = append(rows, []string{mark, k.Version, pkg, strings.Join(boot, ","), kernelStatus(k)})
+	}
+	c.outputTable([]string{"", "VERSION", "PACKAGE", "BOOT", "STATUS"}, rows)
+	return 0
+}
@@ -0,0 +1,80 @@
+package command
+
+import (
+	"fmt"
+)
+
+type KernelPinCommand struct {
+	KernelCommand
+}
+
+func (c *KernelPinCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args, true)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup kernel command: %s", err))
+		return exitCode
+	}
+	pins, err := LoadKernelPins(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load kernel pins: %s", err))
+		return exitCode
+	}
+	if cOpts.Get("remove") != nil {
+		if !c.contains(pins, c.Version) {
+			c.UI.Error(fmt.Sprintf(
+				"Kernel `%s` is not pinned!", c.Version))
+			return exitCode
+		}
+		remaining := []string{}
+		for _, pin := range pins {
+			if pin != c.Version {
+				remaining = append(remaining, pin)
+			}
+		}
+		if err := SaveKernelPins(c.RootDir, remaining); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to save kernel pins: %s", err))
+			return exitCode
+		}
+		c.UI.Info(fmt.Sprintf("Unpinned kernel: %s", c.Version))
+		return 0
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	kernels, err := c.Kernels(db)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to list kernels: %s", err))
+		return exitCode
+	}
+	found := false
+	for _, k := range kernels {
+		found = found || k.Version == c.Version
+	}
+	if !found {
+		c.UI.Error(fmt.Sprintf(
+			"Kernel `%s` is not installed!", c.Version))
+		return exitCode
+	}
+	if !c.contains(pins, c.Version) {
+		pins = append(pins, c.Version)
+		if err := SaveKernelPins(c.RootDir, pins); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to save kernel pins: %s", err))
+			return exitCode
+		}
+	}
+	c.UI.Info(fmt.Sprintf("Pinned kernel: %s", c.Version))
+	return 0
+}
@@ -0,0 +1,124 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type KernelPurgeCommand struct {
+	KernelCommand
+}
+
+func (c *KernelPurgeCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup kernel command: %s", err))
+		return exitCode
+	}
+	dryRun := cOpts.Get("dry-run") != nil
+	if !dryRun && !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	kernels, err := c.Kernels(db)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to list kernels: %s", err))
+		return exitCode
+	}
+	if c.RootDir == "/" && c.RunningKernel() == "" {
+		c.UI.Error("Failed to determine the running kernel, refusing to purge!")
+		return exitCode
+	}
+	purge, err := c.purgeCandidates(kernels, cOpts.Args)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	if len(purge) == 0 {
+		c.UI.Info("No kernels to purge")
+		return 0
+	}
+	rows := [][]string{}
+	for _, k := range purge {
+		action := "remove files"
+		if !k.Orphaned {
+			action = "remove package " + k.Package
+		}
+		rows = append(rows, []string{k.Version, action, strings.Join(k.BootFiles, " ")})
+	}
+	c.outputTable([]string{"VERSION", "ACTION", "BOOT FILES"}, rows)
+	if dryRun {
+		return 0
+	}
+	if cOpts.Get("yes") == nil {
+		ok, err := c.confirm(fmt.Sprintf(
+			"Purge %d kernels?", len(purge)))
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to read confirmation: %s", err))
+			return exitCode
+		}
+		if !ok {
+			c.UI.Warn("Purge aborted.")
+			return exitCode
+		}
+	}
+	for _, k := range purge {
+		if k.Orphaned {
+			err = c.RemoveKernelFiles(k)
+		} else {
+			_, err = c.xbpsOutput(XBPS_REMOVE_PATH, "-y", k.Package)
+		}
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to purge kernel `%s`: %s", k.Version, err))
+			return exitCode
+		}
+		c.UI.Info(fmt.Sprintf("Purged kernel: %s", k.Version))
+	}
+	return 0
+}
+
+// Selects the kernels to purge. Explicitly requested kernels are
+// matched by version or package name and may also be packaged ones,
+// otherwise all orphaned kernels that are not protected are selected.
+func (c *KernelCommand) purgeCandidates(kernels []*Kernel, args []string) ([]*Kernel, error) {
+	purge := []*Kernel{}
+	if len(args) == 0 {
+		for _, k := range kernels {
+			if k.Orphaned && c.Protected(k) == "" {
+				purge = append(purge, k)
+			}
+		}
+		return purge, nil
+	}
+	selected := map[*Kernel]bool{}
+	for _, version := range args {
+		var match *Kernel
+		for _, k := range kernels {
+			if k.Version == version || k.Package == version {
+				match = k
+			}
+		}
+		if match == nil {
+			return nil, fmt.Errorf("Kernel `%s` is not installed!", version)
+		}
+		if reason := c.Protected(match); reason != "" {
+			return nil, fmt.Errorf("Kernel `%s` cannot be purged: %s!", match.Version, reason)
+		}
+		if !selected[match] {
+			selected[match] = true
+			purge = append(purge, match)
+		}
+	}
+	return purge, nil
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKernelsOrdering(t *testing.T) {
+	root, err := ioutil.TempDir("", "void-kernel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	versions := []string{"6.1.1_2", "5.10.10_1", "6.1.1_1", "5.10.9_1", "6.1.1rc1_1"}
+	for _, version := range versions {
+		if err := os.MkdirAll(filepath.Join(root, KERNEL_MODULES_PATH, version), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := &PkgDB{
+		RootDir: root,
+		Packages: map[string]*Package{
+			"linux6.1": &Package{Name: "linux6.1", Version: "6.1.1_2", PkgVer: "linux6.1-6.1.1_2"}}}
+	c := &KernelCommand{}
+	c.RootDir = root
+	kernels, err := c.Kernels(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"5.10.9_1", "5.10.10_1", "6.1.1rc1_1", "6.1.1_1", "6.1.1_2"}
+	if len(kernels) != len(expected) {
+		t.Fatalf("Kernels() returned %d kernels, expected %d", len(kernels), len(expected))
+	}
+	for idx, k := range kernels {
+		if k.Version != expected[idx] {
+			t.Errorf("Kernels()[%d] = %s, expected %s", idx, k.Version, expected[idx])
+		}
+	}
+	if newest := kernels[len(kernels)-1]; newest.Package != "linux6.1" || newest.Orphaned {
+		t.Errorf("Newest kernel %s not owned by linux6.1", newest.Version)
+	}
+}
+
+func TestKernelProtected(t *testing.T) {
+	cases := []struct {
+		kernel *Kernel
+		reason string
+	}{
+		{&Kernel{Version: "6.1.1_1", Running: true, Pinned: true}, "running kernel"},
+		{&Kernel{Version: "6.1.1_1", Pinned: true}, "pinned"},
+		{&Kernel{Version: "6.1.1_1", RequiredBy: []string{"linux", "zfs"}}, "required by linux,zfs"},
+		{&Kernel{Version: "6.1.1_1", Orphaned: true}, ""},
+	}
+	c := &KernelCommand{}
+	for _, tc := range cases {
+		if reason := c.Protected(tc.kernel); reason != tc.reason {
+			t.Errorf("Protected(%+v) = %q, expected %q", tc.kernel, reason, tc.reason)
+		}
+	}
+}
+
+func TestKernelPurgeCandidates(t *testing.T) {
+	kernels := []*Kernel{
+		&Kernel{Version: "5.10.9_1", Orphaned: true},
+		&Kernel{Version: "5.10.10_1", Orphaned: true, Pinned: true},
+		&Kernel{Version: "6.1.1_1", Orphaned: true, Running: true},
+		&Kernel{Version: "6.1.1_2", Package: "linux6.1"},
+		&Kernel{Version: "6.6.1_1", Package: "linux6.6", RequiredBy: []string{"linux"}}}
+	c := &KernelCommand{}
+	versions := func(purge []*Kernel) []string {
+		result := []string{}
+		for _, k := range purge {
+			result = append(result, k.Version)
+		}
+		return result
+	}
+	purge, err := c.purgeCandidates(kernels, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := versions(purge); len(result) != 1 || result[0] != "5.10.9_1" {
+		t.Errorf("purgeCandidates() = %v, expected only unprotected orphans", result)
+	}
+	purge, err = c.purgeCandidates(kernels, []string{"linux6.1", "6.1.1_2", "5.10.9_1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := versions(purge); len(result) != 2 || result[0] != "6.1.1_2" || result[1] != "5.10.9_1" {
+		t.Errorf("purgeCandidates() with duplicates = %v", result)
+	}
+	for _, args := range [][]string{{"6.1.1_1"}, {"5.10.10_1"}, {"linux6.6"}, {"4.19.1_1"}} {
+		if _, err := c.purgeCandidates(kernels, args); err == nil {
+			t.Errorf("purgeCandidates(%v) did not fail", args)
+		}
+	}
+}
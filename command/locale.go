@@ -0,0 +1,165 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const LIBC_LOCALES_PATH = "/etc/default/libc-locales"
+const LOCALE_CONF_PATH = "/etc/locale.conf"
+const LOCALTIME_PATH = "/etc/localtime"
+const HOSTNAME_PATH = "/etc/hostname"
+const GLIBC_LOCALES_PKG = "glibc-locales"
+
+// Locale entry of the libc-locales file (`#en_US.UTF-8 UTF-8`)
+type Locale struct {
+	Name    string `json:"name"`
+	Charset string `json:"charset"`
+	Enabled bool   `json:"enabled"`
+	Line    int    `json:"-"`
+}
+
+// The libc-locales file kept as lines to preserve its layout
+type LibcLocales struct {
+	Path  string
+	Lines []string
+}
+
+func LoadLibcLocales(rootDir string) (*LibcLocales, error) {
+	path := filepath.Join(rootDir, LIBC_LOCALES_PATH)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s not found (locales are only generated on glibc systems)", LIBC_LOCALES_PATH)
+		}
+		return nil, err
+	}
+	return &LibcLocales{Path: path, Lines: splitLines(string(content))}, nil
+}
+
+// Locale entries. Comment lines which are not `NAME CHARSET` pairs,
+// like the file header, are skipped.
+func (l *LibcLocales) Locales() []Locale {
+	locales := []Locale{}
+	for idx, line := range l.Lines {
+		enabled := !strings.HasPrefix(strings.TrimSpace(line), "#")
+		fields := strings.Fields(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		if len(fields) != 2 || !strings.Contains(fields[0], "_") && fields[0] != "C.UTF-8" {
+			continue
+		}
+		locales = append(locales, Locale{
+			Name:    fields[0],
+			Charset: fields[1],
+			Enabled: enabled,
+			Line:    idx})
+	}
+	return locales
+}
+
+func (l *LibcLocales) Find(name string) (Locale, bool) {
+	for _, locale := range l.Locales() {
+		if locale.Name == name {
+			return locale, true
+		}
+	}
+	return Locale{}, false
+}
+
+// Enables or disables a locale, returning if the file was changed
+func (l *LibcLocales) SetEnabled(name string, enabled bool) (bool, error) {
+	locale, ok := l.Find(name)
+	if !ok {
+		return false, fmt.Errorf("Unknown locale `%s`", name)
+	}
+	if locale.Enabled == enabled {
+		return false, nil
+	}
+	entry := locale.Name + " " + locale.Charset
+	if !enabled {
+		entry = "#" + entry
+	}
+	l.Lines[locale.Line] = entry
+	return true, nil
+}
+
+func (l *LibcLocales) Save() error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(l.Path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return writeFileAtomic(l.Path, []byte(strings.Join(l.Lines, "\n")+"\n"), mode)
+}
+
+// Regenerates the enabled locales by reconfiguring glibc-locales
+func (c *SystemCommand) GenerateLocales() bool {
+	xArgs := []string{"-f", GLIBC_LOCALES_PKG}
+	if c.RootDir != "/" {
+		xArgs = append([]string{"-r", c.RootDir}, xArgs...)
+	}
+	return c.ExecuteCommand(exec.Command(XBPS_RECONFIGURE_PATH, xArgs...)) == 0
+}
+
+// Enables or disables the given locales and regenerates them when
+// anything changed, unless disabled by the `no-generate` flag
+func (c *SystemCommand) toggleLocales(names []string, enabled bool, cOpts ParsedCli) int {
+	exitCode := 1
+	if len(names) == 0 {
+		c.UI.Error("At least one locale required!")
+		return exitCode
+	}
+	locales, err := LoadLibcLocales(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load locales: %s", err))
+		return exitCode
+	}
+	changed := false
+	for _, name := range names {
+		updated, err := locales.SetEnabled(name, enabled)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return exitCode
+		}
+		changed = changed || updated
+	}
+	action := "Enabled"
+	if !enabled {
+		action = "Disabled"
+	}
+	if !changed {
+		c.UI.Info(fmt.Sprintf("%s locales: %s (unchanged)", action, strings.Join(names, " ")))
+		return 0
+	}
+	if err := locales.Save(); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write locales: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf("%s locales: %s", action, strings.Join(names, " ")))
+	if cOpts.Get("no-generate") == nil {
+		if !c.GenerateLocales() {
+			c.UI.Error("Failed to generate locales!")
+			return exitCode
+		}
+		c.UI.Info("Generated locales")
+	}
+	return 0
+}
+
+// LANG configured in locale.conf
+func (c *SystemCommand) currentLocale() string {
+	content, err := ioutil.ReadFile(filepath.Join(c.RootDir, LOCALE_CONF_PATH))
+	if err != nil {
+		return ""
+	}
+	for _, line := range splitLines(string(content)) {
+		if strings.HasPrefix(strings.TrimSpace(line), "LANG=") {
+			return unquoteShellValue(strings.SplitN(line, "=", 2)[1])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,290 @@
+package command
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"github.com/mitchellh/cli"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const LOG_PATH = "/var/log"
+const SOCKLOG_PATH = "/var/log/socklog"
+
+// Offset of the TAI64 label epoch, including the 10 second TAI-UTC
+// difference at 1970 used by daemontools and runit
+const TAI64_EPOCH = uint64(1)<<62 + 10
+
+// Log command stub
+type LogCommand struct {
+	CoreCommand
+	RootDir string
+}
+
+// svlogd managed log directory
+type LogSource struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Single log line with its decoded timestamp
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Message string    `json:"message"`
+}
+
+func (c *LogCommand) Commands(appName string, ui cli.Ui, debug bool) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"log query": func() (cli.Command, error) {
+			return &LogQueryCommand{
+				LogCommand: LogCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void log query [--facility F] [--since T] [--grep RE] [--follow]",
+						SynopsisText: "Query socklog and svlogd logs",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "facility",
+								Description: "Only show logs of the given facilities or log directories (comma separated)"},
+							CoreFlag{
+								Name:        "since",
+								Description: "Only show entries since a time (YYYY-MM-DD [HH:MM[:SS]]) or duration (30m, 2h, 1d)"},
+							CoreFlag{
+								Name:        "grep",
+								Description: "Only show entries matching a regular expression"},
+							CoreFlag{
+								Name:        "lines",
+								Description: "Only show the last N entries"},
+							CoreFlag{
+								Name:        "follow",
+								Boolean:     true,
+								Description: "Wait for new entries"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output entries as JSON lines"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"log policy set": func() (cli.Command, error) {
+			return &LogPolicySetCommand{
+				LogCommand: LogCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void log policy set DIR|SERVICE",
+						SynopsisText: "Set the svlogd retention policy of a log directory",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "size",
+								Boolean:     false,
+								Description: "Maximum size of the current log file in bytes"},
+							CoreFlag{
+								Name:        "num",
+								Boolean:     false,
+								Description: "Number of rotated log files to keep"},
+							CoreFlag{
+								Name:        "min",
+								Boolean:     false,
+								Description: "Minimum number of rotated log files to keep when space is short"},
+							CoreFlag{
+								Name:        "timeout",
+								Boolean:     false,
+								Description: "Rotate the current log file after this many seconds"},
+							CoreFlag{
+								Name:        "udp",
+								Boolean:     false,
+								Description: "Forward entries to a UDP address (ip[:port])"},
+							CoreFlag{
+								Name:        "udp-only",
+								Boolean:     false,
+								Description: "Only forward entries to a UDP address (ip[:port])"},
+							CoreFlag{
+								Name:        "prefix",
+								Boolean:     false,
+								Description: "Prefix for forwarded entries"},
+							CoreFlag{
+								Name:        "processor",
+								Boolean:     false,
+								Description: "Processor run on rotated log files"},
+							CoreFlag{
+								Name:        "filter",
+								Boolean:     false,
+								Description: "Comma separated patterns replacing all filters (+pattern, -pattern)"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"log policy show": func() (cli.Command, error) {
+			return &LogPolicyShowCommand{
+				LogCommand: LogCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void log policy show [DIR|SERVICE]",
+						SynopsisText: "Show log directories with their retention policy and usage",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+	}
+}
+
+// Flags shared by all log commands
+func (c *LogCommand) flags(extra ...CoreFlag) []CoreFlag {
+	return append([]CoreFlag{rootDirFlag()}, extra...)
+}
+
+func (c *LogCommand) Init(args []string) (ParsedCli, error) {
+	fmtOpts, err := c.Parse(args)
+	if err != nil {
+		return fmtOpts, err
+	}
+	c.RootDir = fmtOpts.RootDir()
+	return fmtOpts, nil
+}
+
+// Finds svlogd directories below /var/log, which are directories
+// holding a `current` file. socklog facilities are named
+// `socklog/<facility>`, other directories by their relative path.
+func (c *LogCommand) LogSources() ([]LogSource, error) {
+	base := filepath.Join(c.RootDir, LOG_PATH)
+	sources := []LogSource{}
+	for _, pattern := range []string{"*/current", "*/*/current"} {
+		matches, err := filepath.Glob(filepath.Join(base, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			dir := filepath.Dir(match)
+			name, _ := filepath.Rel(base, dir)
+			sources = append(sources, LogSource{Name: name, Path: dir})
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+	return sources, nil
+}
+
+// Files of a log directory in chronological order: rotated files
+// (`@<tai64n>.s` and `.u`) followed by `current`. Rotated files are
+// named by their rotation time, so those rotated before `since` only
+// hold older entries and are skipped.
+func logFiles(dir string, since time.Time) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, "@*"))
+	sort.Strings(files)
+	result := []string{}
+	for _, file := range files {
+		name := filepath.Base(file)
+		if !since.IsZero() && len(name) >= 25 {
+			if rotated, err := decodeTAI64N(name[1:25]); err == nil && rotated.Before(since) {
+				continue
+			}
+		}
+		result = append(result, file)
+	}
+	return append(result, filepath.Join(dir, "current"))
+}
+
+// Reads the entries of a log directory, skipping rotated files older
+// than `since` when it is set
+func readLogSource(source LogSource, since time.Time) ([]LogEntry, error) {
+	entries := []LogEntry{}
+	for _, path := range logFiles(source.Path, since) {
+		file, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return entries, err
+		}
+		_, err = scanLogEntries(file, source.Name, func(entry LogEntry) {
+			entries = append(entries, entry)
+		})
+		file.Close()
+		if err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
+
+// Parses log lines from a reader, returning the number of bytes of
+// complete lines consumed
+func scanLogEntries(r io.Reader, source string, fn func(LogEntry)) (int64, error) {
+	reader := bufio.NewReader(r)
+	var consumed int64
+	var last time.Time
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return consumed, nil
+		}
+		if err != nil {
+			return consumed, err
+		}
+		consumed = consumed + int64(len(line))
+		line = strings.TrimRight(line, "\n")
+		stamp, message, ok := parseLogTimestamp(line)
+		if !ok {
+			// Lines without timestamps inherit the previous one
+			stamp, message = last, line
+		}
+		last = stamp
+		fn(LogEntry{Time: stamp, Source: source, Message: message})
+	}
+}
+
+// Decodes the svlogd timestamp prefix of a line. Supports TAI64N
+// (`-t`), `YYYY-MM-DD_HH:MM:SS.xxxxx` (`-tt`) and ISO 8601 (`-ttt`).
+func parseLogTimestamp(line string) (time.Time, string, bool) {
+	if strings.HasPrefix(line, "@") && len(line) >= 25 {
+		if stamp, err := decodeTAI64N(line[1:25]); err == nil {
+			return stamp, strings.TrimPrefix(line[25:], " "), true
+		}
+	}
+	idx := strings.Index(line, " ")
+	if idx == -1 {
+		return time.Time{}, line, false
+	}
+	for _, layout := range []string{"2006-01-02_15:04:05.999999999", "2006-01-02T15:04:05.999999999"} {
+		if stamp, err := time.ParseInLocation(layout, line[:idx], time.UTC); err == nil {
+			return stamp, line[idx+1:], true
+		}
+	}
+	return time.Time{}, line, false
+}
+
+func decodeTAI64N(label string) (time.Time, error) {
+	raw, err := hex.DecodeString(label)
+	if err != nil || len(raw) != 12 {
+		return time.Time{}, errors.New("invalid TAI64N label")
+	}
+	var seconds uint64
+	for _, b := range raw[:8] {
+		seconds = seconds<<8 | uint64(b)
+	}
+	var nanos uint32
+	for _, b := range raw[8:] {
+		nanos = nanos<<8 | uint32(b)
+	}
+	if seconds < TAI64_EPOCH || nanos >= 1e9 {
+		return time.Time{}, errors.New("invalid TAI64N label")
+	}
+	return time.Unix(int64(seconds-TAI64_EPOCH), int64(nanos)).UTC(), nil
+}
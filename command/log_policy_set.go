@@ -0,0 +1,127 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type LogPolicySetCommand struct {
+	LogCommand
+}
+
+func (c *LogPolicySetCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup log command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single log directory required!")
+		return exitCode
+	}
+	dirs, err := c.LogDirs()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to find log directories: %s", err))
+		return exitCode
+	}
+	dir, err := c.logDir(dirs, cOpts.Args[0])
+	if err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	if info, err := os.Stat(filepath.Join(c.RootDir, dir.Path)); err != nil || !info.IsDir() {
+		c.UI.Error(fmt.Sprintf(
+			"Log directory `%s` does not exist!", dir.Path))
+		return exitCode
+	}
+	config, err := LoadSvlogdConfig(filepath.Join(c.RootDir, dir.Path))
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load log config: %s", err))
+		return exitCode
+	}
+	changed := false
+	for _, name := range []string{"size", "num", "min", "timeout"} {
+		if flag := cOpts.Get(name); flag != nil {
+			if flag.Value != "" {
+				if value, err := strconv.Atoi(flag.Value); err != nil || value < 0 {
+					c.UI.Error(fmt.Sprintf(
+						"Invalid %s value `%s`", name, flag.Value))
+					return exitCode
+				}
+			}
+			config.Set(name, flag.Value)
+			changed = true
+		}
+	}
+	if cOpts.Get("udp") != nil && cOpts.Get("udp-only") != nil {
+		c.UI.Error("Only one of `--udp` or `--udp-only` may be used!")
+		return exitCode
+	}
+	for _, name := range []string{"udp", "udp-only", "prefix", "processor"} {
+		if flag := cOpts.Get(name); flag != nil {
+			if (name == "udp" || name == "udp-only") && flag.Value != "" {
+				if err := validateUDPTarget(flag.Value); err != nil {
+					c.UI.Error(err.Error())
+					return exitCode
+				}
+			}
+			config.Set(name, flag.Value)
+			changed = true
+		}
+	}
+	if flag := cOpts.Get("filter"); flag != nil {
+		filters := []string{}
+		for _, filter := range strings.Split(flag.Value, ",") {
+			if filter == "" {
+				continue
+			}
+			if !strings.HasPrefix(filter, "+") && !strings.HasPrefix(filter, "-") &&
+				!strings.HasPrefix(filter, "e+") && !strings.HasPrefix(filter, "e-") {
+				c.UI.Error(fmt.Sprintf(
+					"Invalid filter `%s` (must start with +, -, e+ or e-)", filter))
+				return exitCode
+			}
+			filters = append(filters, filter)
+		}
+		config.SetFilters(filters)
+		changed = true
+	}
+	if !changed {
+		c.UI.Error("No policy settings given!")
+		return exitCode
+	}
+	if err := config.Save(); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write log config: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf("Updated log policy: %s", dir.Path))
+	if dir.Service == "" || !dir.Enabled {
+		c.UI.Warn("No running log service found, the policy applies on next start")
+		return 0
+	}
+	if c.RootDir != "/" {
+		return 0
+	}
+	// svlogd rereads its configuration on SIGHUP
+	logService := filepath.Join(ENABLED_SERVICES_PATH, dir.Service, "log")
+	if c.ExecuteCommand(exec.Command(SV_PATH, "hup", logService)) != 0 {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to reload log service `%s`!", logService))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf("Reloaded log service: %s", logService))
+	return 0
+}
@@ -0,0 +1,106 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type LogPolicyShowCommand struct {
+	LogCommand
+}
+
+func (c *LogPolicyShowCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup log command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) > 1 {
+		c.UI.Error("At most one log directory allowed!")
+		return exitCode
+	}
+	dirs, err := c.LogDirs()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to find log directories: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) == 1 {
+		dir, err := c.logDir(dirs, cOpts.Args[0])
+		if err != nil {
+			c.UI.Error(err.Error())
+			return exitCode
+		}
+		dirs = []*LogDir{dir}
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(dirs); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	rows := [][]string{}
+	var total int64
+	for _, dir := range dirs {
+		service := dir.Service
+		if service == "" {
+			service = "-"
+		} else if !dir.Enabled {
+			service = service + " (disabled)"
+		}
+		policy := []string{}
+		for _, name := range []string{"size", "num", "min", "timeout", "udp", "udp-only", "prefix", "processor"} {
+			if value, ok := dir.Settings[name]; ok {
+				policy = append(policy, name+"="+value)
+			}
+		}
+		if len(dir.Filters) > 0 {
+			policy = append(policy, fmt.Sprintf("filters=%d", len(dir.Filters)))
+		}
+		if len(policy) == 0 {
+			policy = append(policy, "defaults")
+		}
+		total = total + dir.Size
+		rows = append(rows, []string{dir.Path, service, humanSize(dir.Size), strings.Join(policy, " ")})
+	}
+	c.outputTable([]string{"DIRECTORY", "SERVICE", "USAGE", "POLICY"}, rows)
+	if len(dirs) == 1 && len(dirs[0].Filters) > 0 {
+		c.UI.Output("")
+		c.UI.Output("Filters:")
+		for _, filter := range dirs[0].Filters {
+			c.UI.Output("  " + filter)
+		}
+	}
+	if len(dirs) > 1 {
+		c.UI.Output("")
+		c.UI.Output(fmt.Sprintf("Total usage: %s", humanSize(total)))
+	}
+	return 0
+}
+
+// Finds a log directory by path or by its service name. Directories
+// not referenced by any log script can still be addressed by path.
+func (c *LogCommand) logDir(dirs []*LogDir, arg string) (*LogDir, error) {
+	for _, dir := range dirs {
+		if dir.Path == filepath.Clean(arg) || dir.Service == arg {
+			return dir, nil
+		}
+	}
+	if filepath.IsAbs(arg) {
+		config, err := LoadSvlogdConfig(filepath.Join(c.RootDir, arg))
+		if err != nil {
+			return nil, err
+		}
+		return &LogDir{
+			Path:     filepath.Clean(arg),
+			Size:     logDirSize(filepath.Join(c.RootDir, arg)),
+			Settings: config.Settings(),
+			Filters:  config.Filters()}, nil
+	}
+	return nil, fmt.Errorf("Unknown log directory `%s`", arg)
+}
@@ -0,0 +1,193 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const LOG_FOLLOW_INTERVAL = time.Second
+
+type LogQueryCommand struct {
+	LogCommand
+	since   time.Time
+	pattern *regexp.Regexp
+	asJSON  bool
+}
+
+func (c *LogQueryCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup log command: %s", err))
+		return exitCode
+	}
+	if flag := cOpts.Get("since"); flag != nil {
+		if c.since, err = parseLogSince(flag.Value, time.Now()); err != nil {
+			c.UI.Error(err.Error())
+			return exitCode
+		}
+	}
+	if flag := cOpts.Get("grep"); flag != nil {
+		if c.pattern, err = regexp.Compile(flag.Value); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Invalid pattern: %s", err))
+			return exitCode
+		}
+	}
+	lines := 0
+	if flag := cOpts.Get("lines"); flag != nil {
+		if lines, err = strconv.Atoi(flag.Value); err != nil || lines < 0 {
+			c.UI.Error(fmt.Sprintf(
+				"Invalid lines value `%s`", flag.Value))
+			return exitCode
+		}
+	}
+	c.asJSON = cOpts.Get("json") != nil
+	sources, err := c.LogSources()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to find log directories: %s", err))
+		return exitCode
+	}
+	if flag := cOpts.Get("facility"); flag != nil {
+		sources = filterLogSources(sources, strings.Split(flag.Value, ","))
+	}
+	if len(sources) == 0 {
+		c.UI.Error("No log directories found!")
+		return exitCode
+	}
+	entries := []LogEntry{}
+	for _, source := range sources {
+		sourceEntries, err := readLogSource(source, c.since)
+		if err != nil {
+			c.UI.Warn(fmt.Sprintf(
+				"Failed to read logs of `%s`: %s", source.Name, err))
+		}
+		for _, entry := range sourceEntries {
+			if c.match(entry) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	if lines > 0 && len(entries) > lines {
+		entries = entries[len(entries)-lines:]
+	}
+	for _, entry := range entries {
+		c.output(entry)
+	}
+	if cOpts.Get("follow") != nil {
+		return c.follow(sources)
+	}
+	return 0
+}
+
+func (c *LogQueryCommand) match(entry LogEntry) bool {
+	if !c.since.IsZero() && entry.Time.Before(c.since) {
+		return false
+	}
+	return c.pattern == nil || c.pattern.MatchString(entry.Message)
+}
+
+func (c *LogQueryCommand) output(entry LogEntry) {
+	if c.asJSON {
+		content, _ := json.Marshal(entry)
+		c.UI.Output(string(content))
+		return
+	}
+	c.UI.Output(fmt.Sprintf("%s %s: %s",
+		entry.Time.Local().Format("2006-01-02 15:04:05.000"), entry.Source, entry.Message))
+}
+
+// Polls the `current` files of all sources for new entries. Rotation
+// is detected by the file shrinking or being replaced.
+func (c *LogQueryCommand) follow(sources []LogSource) int {
+	type position struct {
+		offset int64
+		info   os.FileInfo
+	}
+	positions := map[string]*position{}
+	for _, source := range sources {
+		path := filepath.Join(source.Path, "current")
+		if info, err := os.Stat(path); err == nil {
+			positions[path] = &position{offset: info.Size(), info: info}
+		} else {
+			positions[path] = &position{}
+		}
+	}
+	for {
+		time.Sleep(LOG_FOLLOW_INTERVAL)
+		for _, source := range sources {
+			path := filepath.Join(source.Path, "current")
+			pos := positions[path]
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if pos.info == nil || !os.SameFile(pos.info, info) || info.Size() < pos.offset {
+				pos.offset = 0
+			}
+			pos.info = info
+			if info.Size() == pos.offset {
+				continue
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			if _, err := file.Seek(pos.offset, 0); err == nil {
+				consumed, _ := scanLogEntries(file, source.Name, func(entry LogEntry) {
+					if c.match(entry) {
+						c.output(entry)
+					}
+				})
+				pos.offset = pos.offset + consumed
+			}
+			file.Close()
+		}
+	}
+}
+
+// Matches sources by full name, socklog facility or base name
+func filterLogSources(sources []LogSource, names []string) []LogSource {
+	filtered := []LogSource{}
+	for _, source := range sources {
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			if source.Name == name || filepath.Base(source.Name) == name {
+				filtered = append(filtered, source)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// Parses a since value as a duration before now (with `d` for days)
+// or as a local date and time
+func parseLogSince(value string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02", time.RFC3339} {
+		if stamp, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return stamp, nil
+		}
+	}
+	return now, fmt.Errorf("Invalid since value `%s`", value)
+}
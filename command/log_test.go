@@ -0,0 +1,84 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeTAI64N(t *testing.T) {
+	cases := []struct {
+		label    string
+		expected time.Time
+		valid    bool
+	}{
+		{"400000000000000a00000000", time.Unix(0, 0).UTC(), true},
+		{"400000005f5e100a1dcd6500", time.Date(2020, 9, 13, 12, 26, 40, 500000000, time.UTC), true},
+		{"400000005f5e100a3b9aca00", time.Time{}, false},
+		{"000000005f5e100a00000000", time.Time{}, false},
+		{"400000005f5e100a", time.Time{}, false},
+		{"400000005f5e100a1dcd65zz", time.Time{}, false},
+	}
+	for _, tc := range cases {
+		stamp, err := decodeTAI64N(tc.label)
+		if (err == nil) != tc.valid {
+			t.Errorf("decodeTAI64N(%q) error = %v, expected valid %t", tc.label, err, tc.valid)
+			continue
+		}
+		if tc.valid && !stamp.Equal(tc.expected) {
+			t.Errorf("decodeTAI64N(%q) = %s, expected %s", tc.label, stamp, tc.expected)
+		}
+	}
+}
+
+func TestParseLogTimestamp(t *testing.T) {
+	stamp := time.Date(2020, 9, 13, 12, 26, 40, 500000000, time.UTC)
+	cases := []struct {
+		line    string
+		stamp   time.Time
+		message string
+		ok      bool
+	}{
+		{"@400000005f5e100a1dcd6500 sshd: accepted", stamp, "sshd: accepted", true},
+		{"2020-09-13_12:26:40.50000 sshd: accepted", stamp, "sshd: accepted", true},
+		{"2020-09-13T12:26:40.50000 sshd: accepted", stamp, "sshd: accepted", true},
+		{"2020-09-13_12:26:40 sshd: accepted", stamp.Truncate(time.Second), "sshd: accepted", true},
+		{"@400000005f5e100a sshd: accepted", time.Time{}, "@400000005f5e100a sshd: accepted", false},
+		{"sshd: accepted", time.Time{}, "sshd: accepted", false},
+		{"continued", time.Time{}, "continued", false},
+	}
+	for _, tc := range cases {
+		stamp, message, ok := parseLogTimestamp(tc.line)
+		if ok != tc.ok || !stamp.Equal(tc.stamp) || message != tc.message {
+			t.Errorf("parseLogTimestamp(%q) = %s, %q, %t, expected %s, %q, %t",
+				tc.line, stamp, message, ok, tc.stamp, tc.message, tc.ok)
+		}
+	}
+}
+
+func TestLogFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "void-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// Rotated 2020-09-13 and 2023-11-14
+	older := filepath.Join(dir, "@400000005f5e100a00000000.s")
+	newer := filepath.Join(dir, "@400000006553f10a00000000.u")
+	for _, path := range []string{newer, older, filepath.Join(dir, "current")} {
+		if err := ioutil.WriteFile(path, []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	current := filepath.Join(dir, "current")
+	if files := logFiles(dir, time.Time{}); !reflect.DeepEqual(files, []string{older, newer, current}) {
+		t.Errorf("logFiles() = %v", files)
+	}
+	since := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	if files := logFiles(dir, since); !reflect.DeepEqual(files, []string{newer, current}) {
+		t.Errorf("logFiles() since %s = %v", since, files)
+	}
+}
@@ -0,0 +1,257 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Single letter svlogd config settings and their names
+var svlogdSettings = map[string]string{
+	"s": "size",
+	"n": "num",
+	"N": "min",
+	"t": "timeout",
+	"!": "processor",
+	"u": "udp",
+	"U": "udp-only",
+	"p": "prefix"}
+
+// Options of svlogd taking a value, skipped when parsing log scripts
+var svlogdValueOptions = map[string]bool{"-r": true, "-R": true, "-l": true, "-b": true}
+
+// svlogd `config` file kept as lines to preserve unknown entries
+type SvlogdConfig struct {
+	Path  string
+	Lines []string
+}
+
+// Log directory written by a service log script
+type LogDir struct {
+	Path     string            `json:"path"`
+	Service  string            `json:"service,omitempty"`
+	Enabled  bool              `json:"enabled"`
+	Size     int64             `json:"size"`
+	Settings map[string]string `json:"settings"`
+	Filters  []string          `json:"filters,omitempty"`
+}
+
+func LoadSvlogdConfig(dir string) (*SvlogdConfig, error) {
+	config := &SvlogdConfig{Path: filepath.Join(dir, "config"), Lines: []string{}}
+	content, err := ioutil.ReadFile(config.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, err
+	}
+	config.Lines = splitLines(string(content))
+	return config, nil
+}
+
+// Known settings keyed by their name
+func (s *SvlogdConfig) Settings() map[string]string {
+	settings := map[string]string{}
+	for _, line := range s.Lines {
+		if line == "" {
+			continue
+		}
+		if name, ok := svlogdSettings[line[:1]]; ok {
+			settings[name] = line[1:]
+		}
+	}
+	return settings
+}
+
+// Pattern lines (`+pattern`, `-pattern`, `e+pattern`, `e-pattern`)
+func (s *SvlogdConfig) Filters() []string {
+	filters := []string{}
+	for _, line := range s.Lines {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") ||
+			strings.HasPrefix(line, "e+") || strings.HasPrefix(line, "e-") {
+			filters = append(filters, line)
+		}
+	}
+	return filters
+}
+
+// Sets a named setting, removing it when the value is empty. `udp`
+// and `udp-only` are mutually exclusive as are their svlogd letters.
+func (s *SvlogdConfig) Set(name string, value string) {
+	letter := ""
+	for key, setting := range svlogdSettings {
+		if setting == name {
+			letter = key
+		}
+	}
+	remove := map[string]bool{letter: true}
+	if letter == "u" || letter == "U" {
+		remove["u"], remove["U"] = true, true
+	}
+	lines := []string{}
+	placed := false
+	for _, line := range s.Lines {
+		if line != "" && remove[line[:1]] {
+			if !placed && value != "" {
+				lines = append(lines, letter+value)
+				placed = true
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if !placed && value != "" {
+		lines = append(lines, letter+value)
+	}
+	s.Lines = lines
+}
+
+// Checks an svlogd UDP target, which is an IPv4 address with an
+// optional port
+func validateUDPTarget(value string) error {
+	host, port := value, ""
+	if idx := strings.LastIndex(value, ":"); idx != -1 {
+		host, port = value[:idx], value[idx+1:]
+		if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+			return fmt.Errorf("Invalid UDP port `%s`", port)
+		}
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.To4() == nil || strings.Contains(host, ":") {
+		return fmt.Errorf("Invalid UDP address `%s` (expected IPv4 address with optional port)", value)
+	}
+	return nil
+}
+
+// Replaces all pattern lines with the given filters
+func (s *SvlogdConfig) SetFilters(filters []string) {
+	lines := []string{}
+	for _, line := range s.Lines {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") ||
+			strings.HasPrefix(line, "e+") || strings.HasPrefix(line, "e-") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	s.Lines = append(lines, filters...)
+}
+
+func (s *SvlogdConfig) Save() error {
+	content := strings.Join(s.Lines, "\n")
+	if len(s.Lines) > 0 {
+		content = content + "\n"
+	}
+	return writeFileAtomic(s.Path, []byte(content), 0644)
+}
+
+// Finds the log directories written by svlogd from the log scripts
+// of all services. Paths are relative to the configured root.
+func (c *LogCommand) LogDirs() ([]*LogDir, error) {
+	scripts, err := filepath.Glob(filepath.Join(c.RootDir, SERVICES_PATH, "*", "log", "run"))
+	if err != nil {
+		return nil, err
+	}
+	dirs := map[string]*LogDir{}
+	for _, script := range scripts {
+		service := filepath.Base(filepath.Dir(filepath.Dir(script)))
+		_, err := os.Lstat(filepath.Join(c.RootDir, ENABLED_SERVICES_PATH, service))
+		enabled := err == nil
+		for _, dir := range c.scriptLogDirs(script) {
+			if _, ok := dirs[dir]; ok {
+				continue
+			}
+			logDir := &LogDir{Path: dir, Service: service, Enabled: enabled}
+			config, err := LoadSvlogdConfig(filepath.Join(c.RootDir, dir))
+			if err != nil {
+				return nil, err
+			}
+			logDir.Settings = config.Settings()
+			logDir.Filters = config.Filters()
+			logDir.Size = logDirSize(filepath.Join(c.RootDir, dir))
+			dirs[dir] = logDir
+		}
+	}
+	result := []*LogDir{}
+	for _, dir := range dirs {
+		result = append(result, dir)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result, nil
+}
+
+// Log directories passed to svlogd in a log run script. Relative
+// directories are relative to the log service directory and globs are
+// expanded like the shell would.
+func (c *LogCommand) scriptLogDirs(script string) []string {
+	dirs := []string{}
+	file, err := os.Open(script)
+	if err != nil {
+		return dirs
+	}
+	defer file.Close()
+	serviceDir := strings.TrimPrefix(filepath.Dir(script), filepath.Clean(c.RootDir))
+	if !strings.HasPrefix(serviceDir, "/") {
+		serviceDir = "/" + serviceDir
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		start := -1
+		for idx, field := range fields {
+			if filepath.Base(field) == "svlogd" {
+				start = idx + 1
+				break
+			}
+		}
+		if start == -1 {
+			continue
+		}
+		for idx := start; idx < len(fields); idx++ {
+			// A command separator directly after a directory ends the
+			// svlogd arguments
+			last := strings.HasSuffix(fields[idx], ";") || strings.HasSuffix(fields[idx], "&")
+			field := strings.Trim(strings.TrimRight(fields[idx], ";&"), `"'`)
+			if strings.HasPrefix(field, "-") {
+				if svlogdValueOptions[field] {
+					idx++
+				}
+				continue
+			}
+			if field == "" || strings.ContainsAny(field, "|;&>$") {
+				break
+			}
+			if !filepath.IsAbs(field) {
+				field = filepath.Join(serviceDir, field)
+			}
+			matches, _ := filepath.Glob(filepath.Join(c.RootDir, field))
+			if len(matches) == 0 {
+				dirs = append(dirs, filepath.Clean(field))
+			}
+			for _, match := range matches {
+				rel, _ := filepath.Rel(c.RootDir, match)
+				dirs = append(dirs, "/"+rel)
+			}
+			if last {
+				break
+			}
+		}
+	}
+	return dirs
+}
+
+// Size of a log directory in bytes
+func logDirSize(dir string) int64 {
+	var size int64
+	files, _ := ioutil.ReadDir(dir)
+	for _, info := range files {
+		if info.Mode().IsRegular() {
+			size = size + info.Size()
+		}
+	}
+	return size
+}
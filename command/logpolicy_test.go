@@ -0,0 +1,91 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSvlogdConfigSet(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{"size", "100000", []string{"s100000", "n10", "u10.0.0.1:514", "+*", "-*debug*"}},
+		{"num", "", []string{"s4096", "u10.0.0.1:514", "+*", "-*debug*"}},
+		{"timeout", "3600", []string{"s4096", "n10", "u10.0.0.1:514", "+*", "-*debug*", "t3600"}},
+		{"udp-only", "10.0.0.2", []string{"s4096", "n10", "U10.0.0.2", "+*", "-*debug*"}},
+		{"udp", "", []string{"s4096", "n10", "+*", "-*debug*"}},
+	}
+	for _, tc := range cases {
+		config := &SvlogdConfig{Lines: []string{"s4096", "n10", "u10.0.0.1:514", "+*", "-*debug*"}}
+		config.Set(tc.name, tc.value)
+		if !reflect.DeepEqual(config.Lines, tc.expected) {
+			t.Errorf("Set(%q, %q) lines = %q, expected %q", tc.name, tc.value, config.Lines, tc.expected)
+		}
+	}
+	config := &SvlogdConfig{Lines: []string{"U10.0.0.2", "u10.0.0.1"}}
+	config.Set("udp", "10.0.0.3:514")
+	if !reflect.DeepEqual(config.Lines, []string{"u10.0.0.3:514"}) {
+		t.Errorf("Set(udp) did not replace both udp settings: %q", config.Lines)
+	}
+	settings := config.Settings()
+	if settings["udp"] != "10.0.0.3:514" || settings["udp-only"] != "" {
+		t.Errorf("Settings() = %v", settings)
+	}
+}
+
+func TestValidateUDPTarget(t *testing.T) {
+	cases := map[string]bool{
+		"10.0.0.1":       true,
+		"10.0.0.1:514":   true,
+		"10.0.0.1:0":     false,
+		"10.0.0.1:65536": false,
+		"10.0.0.1:":      false,
+		"10.0.0":         false,
+		"loghost:514":    false,
+		"::1":            false,
+		"[::1]:514":      false,
+	}
+	for value, valid := range cases {
+		if err := validateUDPTarget(value); (err == nil) != valid {
+			t.Errorf("validateUDPTarget(%q) error = %v, expected valid %t", value, err, valid)
+		}
+	}
+}
+
+func TestScriptLogDirs(t *testing.T) {
+	root, err := ioutil.TempDir("", "void-logpolicy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for _, dir := range []string{"var/log/socklog/kern", "var/log/socklog/user", "etc/sv/foo/log/main"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		script   string
+		expected []string
+	}{
+		{"#!/bin/sh\nexec vlogger -t foo\n", []string{}},
+		{"#!/bin/sh\nexec svlogd -tt /var/log/foo\n", []string{"/var/log/foo"}},
+		{"#!/bin/sh\nexec chpst -ulog /usr/bin/svlogd -tt -l 1000 -b 4096 ./main\n", []string{"/etc/sv/foo/log/main"}},
+		{"#!/bin/sh\nexec svlogd -ttt '/var/log/socklog/*' 2>/dev/null\n", []string{"/var/log/socklog/kern", "/var/log/socklog/user"}},
+		{"#!/bin/sh\nexec svlogd -r _ \"/var/log/bar\" /var/log/baz; /var/log/qux\n", []string{"/var/log/bar", "/var/log/baz"}},
+	}
+	script := filepath.Join(root, "etc/sv/foo/log/run")
+	c := &LogCommand{RootDir: root}
+	for _, tc := range cases {
+		if err := ioutil.WriteFile(script, []byte(tc.script), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if dirs := c.scriptLogDirs(script); !reflect.DeepEqual(dirs, tc.expected) {
+			t.Errorf("scriptLogDirs(%q) = %q, expected %q", tc.script, dirs, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+const MANIFEST_VERSION = 1
+
+// Package entry of a manifest
+type ManifestPkg struct {
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	Hold     bool   `json:"hold,omitempty"`
+	RepoLock bool   `json:"repolock,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Manually installed package set of a host
+type PkgManifest struct {
+	Version      int           `json:"version"`
+	Host         string        `json:"host,omitempty"`
+	Created      string        `json:"created,omitempty"`
+	Architecture string        `json:"architecture,omitempty"`
+	Repositories []string      `json:"repositories"`
+	Packages     []ManifestPkg `json:"packages"`
+}
+
+// Builds a manifest of the manually installed packages in the pkgdb
+func NewManifest(db *PkgDB, config *XbpsConfig, notes HoldNotes) *PkgManifest {
+	host, _ := os.Hostname()
+	manifest := &PkgManifest{
+		Version:      MANIFEST_VERSION,
+		Host:         host,
+		Created:      time.Now().UTC().Format(time.RFC3339),
+		Architecture: config.Architecture(),
+		Repositories: []string{},
+		Packages:     []ManifestPkg{}}
+	for _, repo := range config.Repositories() {
+		manifest.Repositories = append(manifest.Repositories, repo.Value)
+	}
+	for _, name := range db.Names() {
+		pkg := db.Packages[name]
+		if pkg.Automatic {
+			continue
+		}
+		manifest.Packages = append(manifest.Packages, ManifestPkg{
+			Name:     name,
+			Version:  pkg.Version,
+			Hold:     pkg.Hold,
+			RepoLock: pkg.RepoLock,
+			Reason:   notes[name].Reason})
+	}
+	return manifest
+}
+
+// Loads a manifest file
+func LoadManifest(path string) (*PkgManifest, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &PkgManifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if manifest.Version != MANIFEST_VERSION {
+		return nil, fmt.Errorf("%s: unsupported manifest version %d", path, manifest.Version)
+	}
+	sort.Slice(manifest.Packages, func(i, j int) bool {
+		return manifest.Packages[i].Name < manifest.Packages[j].Name
+	})
+	return manifest, nil
+}
+
+// Manifest packages keyed by name
+func (m *PkgManifest) PackageMap() map[string]ManifestPkg {
+	pkgs := map[string]ManifestPkg{}
+	for _, pkg := range m.Packages {
+		pkgs[pkg.Name] = pkg
+	}
+	return pkgs
+}
+
+// Writes the manifest to the given path
+func (m *PkgManifest) Save(path string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(content, '\n'), 0644)
+}
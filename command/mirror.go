@@ -0,0 +1,176 @@
+package command
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const MIRROR_PROBE_ATTEMPTS = 3
+const MIRROR_PROBE_TIMEOUT = 2 * time.Second
+
+// Mirrors known at build time, used when no mirror list file is given
+var bundledMirrors = []Mirror{
+	Mirror{URL: "https://repo-default.voidlinux.org", Region: "World (Tier 1)"},
+	Mirror{URL: "https://repo-fastly.voidlinux.org", Region: "World (Fastly CDN)"},
+	Mirror{URL: "https://repo-fi.voidlinux.org", Region: "EU: Finland"},
+	Mirror{URL: "https://repo-de.voidlinux.org", Region: "EU: Germany"},
+	Mirror{URL: "https://repo-us.voidlinux.org", Region: "USA: Kansas City"},
+	Mirror{URL: "https://mirrors.servercentral.com/voidlinux", Region: "USA: Chicago"},
+	Mirror{URL: "https://mirror.clarkson.edu/voidlinux", Region: "USA: New York"},
+	Mirror{URL: "https://mirrors.dotsrc.org/voidlinux", Region: "EU: Denmark"},
+	Mirror{URL: "https://ftp.acc.umu.se/mirror/voidlinux", Region: "EU: Sweden"},
+	Mirror{URL: "https://mirror.aarnet.edu.au/pub/voidlinux", Region: "Oceania: Australia"},
+	Mirror{URL: "https://mirrors.tuna.tsinghua.edu.cn/voidlinux", Region: "Asia: China"},
+	Mirror{URL: "https://repo.jing.rocks/voidlinux", Region: "Asia: Japan"}}
+
+// Repository mirror base URL
+type Mirror struct {
+	URL     string        `json:"url"`
+	Region  string        `json:"region,omitempty"`
+	Latency time.Duration `json:"latency_ns,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Measures the latency of a mirror. Implementations must be safe for
+// concurrent use.
+type MirrorProber interface {
+	Probe(mirror string) (time.Duration, error)
+}
+
+// Probes mirrors by timing TCP connections to the mirror host
+type TCPProber struct {
+	Timeout  time.Duration
+	Attempts int
+}
+
+// Returns the fastest connect time of the configured attempts
+func (p *TCPProber) Probe(mirror string) (time.Duration, error) {
+	u, err := url.Parse(mirror)
+	if err != nil {
+		return 0, err
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	addr := net.JoinHostPort(u.Hostname(), port)
+	best := time.Duration(0)
+	var lastErr error
+	for i := 0; i < p.Attempts; i++ {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", addr, p.Timeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		elapsed := time.Since(start)
+		conn.Close()
+		if best == 0 || elapsed < best {
+			best = elapsed
+		}
+	}
+	if best == 0 {
+		return 0, lastErr
+	}
+	return best, nil
+}
+
+// Returns the given prober, or the TCP prober when none is set
+func mirrorProber(prober MirrorProber) MirrorProber {
+	if prober != nil {
+		return prober
+	}
+	return &TCPProber{
+		Timeout:  MIRROR_PROBE_TIMEOUT,
+		Attempts: MIRROR_PROBE_ATTEMPTS}
+}
+
+// Loads mirrors from a file with one `URL [region]` entry per line.
+// The bundled list is returned when no path is given.
+func LoadMirrors(path string) ([]Mirror, error) {
+	if path == "" {
+		return append([]Mirror{}, bundledMirrors...), nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	mirrors := []Mirror{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if !strings.Contains(fields[0], "://") {
+			return nil, fmt.Errorf("Invalid mirror URL `%s`", fields[0])
+		}
+		mirrors = append(mirrors, Mirror{
+			URL:    strings.TrimRight(fields[0], "/"),
+			Region: strings.Join(fields[1:], " ")})
+	}
+	if len(mirrors) == 0 {
+		return nil, errors.New(fmt.Sprintf("No mirrors found in `%s`", path))
+	}
+	return mirrors, scanner.Err()
+}
+
+// Probes all mirrors concurrently and orders them by latency.
+// Unreachable mirrors are placed last with their error recorded.
+func RankMirrors(mirrors []Mirror, prober MirrorProber) []Mirror {
+	ranked := make([]Mirror, len(mirrors))
+	var wg sync.WaitGroup
+	for i, mirror := range mirrors {
+		wg.Add(1)
+		go func(i int, mirror Mirror) {
+			defer wg.Done()
+			latency, err := prober.Probe(mirror.URL)
+			mirror.Latency = latency
+			if err != nil {
+				mirror.Error = err.Error()
+			}
+			ranked[i] = mirror
+		}(i, mirror)
+	}
+	wg.Wait()
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if (ranked[i].Error == "") != (ranked[j].Error == "") {
+			return ranked[i].Error == ""
+		}
+		return ranked[i].Latency < ranked[j].Latency
+	})
+	return ranked
+}
+
+// Splits an official repository URL into the mirror base and the
+// repository path starting at `/current`
+func splitMirrorURL(repo string) (string, string, bool) {
+	if !strings.Contains(repo, "://") {
+		return "", "", false
+	}
+	idx := strings.Index(repo, "/current")
+	if idx == -1 {
+		return "", "", false
+	}
+	rest := repo[idx:]
+	if rest != "/current" && !strings.HasPrefix(rest, "/current/") {
+		return "", "", false
+	}
+	return strings.TrimRight(repo[:idx], "/"), rest, true
+}
@@ -0,0 +1,66 @@
+package command
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Probes mirrors by timing a request for the repository index
+type httpTestProber struct{}
+
+func (p *httpTestProber) Probe(mirror string) (time.Duration, error) {
+	start := time.Now()
+	resp, err := http.Get(mirror + "/current")
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return time.Since(start), nil
+}
+
+func mirrorStandIn(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestRankMirrors(t *testing.T) {
+	slow := mirrorStandIn(200 * time.Millisecond)
+	defer slow.Close()
+	fast := mirrorStandIn(0)
+	defer fast.Close()
+	medium := mirrorStandIn(100 * time.Millisecond)
+	defer medium.Close()
+	down := mirrorStandIn(0)
+	down.Close()
+	mirrors := []Mirror{
+		Mirror{URL: down.URL, Region: "down"},
+		Mirror{URL: slow.URL, Region: "slow"},
+		Mirror{URL: fast.URL, Region: "fast"},
+		Mirror{URL: medium.URL, Region: "medium"}}
+	ranked := RankMirrors(mirrors, &httpTestProber{})
+	expected := []string{"fast", "medium", "slow", "down"}
+	for idx, mirror := range ranked {
+		if mirror.Region != expected[idx] {
+			t.Errorf("RankMirrors()[%d] = %s, expected %s", idx, mirror.Region, expected[idx])
+		}
+	}
+	if ranked[3].Error == "" {
+		t.Errorf("Unreachable mirror has no error recorded")
+	}
+}
+
+func TestTCPProber(t *testing.T) {
+	server := mirrorStandIn(0)
+	prober := &TCPProber{Timeout: time.Second, Attempts: 2}
+	if latency, err := prober.Probe(server.URL); err != nil || latency <= 0 {
+		t.Errorf("Probe() of running stand-in = %s, %v", latency, err)
+	}
+	server.Close()
+	if _, err := prober.Probe(server.URL); err == nil {
+		t.Errorf("Probe() of closed stand-in succeeded")
+	}
+}
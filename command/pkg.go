@@ -0,0 +1,571 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mitchellh/cli"
+)
+
+// Package command stub
+type PkgCommand struct {
+	CoreCommand
+	RootDir string
+	PkgName string
+}
+
+func (c *PkgCommand) Commands(appName string, ui cli.Ui, debug bool) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"pkg changed-configs": func() (cli.Command, error) {
+			return &PkgChangedConfigsCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg changed-configs [NAME...]",
+						SynopsisText: "Report modified, missing and pending configuration files",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "diff",
+								Boolean:     true,
+								Description: "Show differences against cached package archives"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg clean": func() (cli.Command, error) {
+			return &PkgCleanCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg clean",
+						SynopsisText: "Remove orphaned packages and obsolete cached packages",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "orphans",
+								Boolean:     true,
+								Description: "Only remove orphaned packages"},
+							CoreFlag{
+								Name:        "cache",
+								Boolean:     true,
+								Description: "Only remove obsolete cached packages"},
+							CoreFlag{
+								Name:        "yes",
+								Boolean:     true,
+								Description: "Do not ask for confirmation"},
+							CoreFlag{
+								Name:        "dry-run",
+								Boolean:     true,
+								Description: "Only display what would be removed"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg deps": func() (cli.Command, error) {
+			return &PkgDepsCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg deps NAME [--reverse] [--tree|--dot] [--why]",
+						SynopsisText: "Explore package dependencies",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "reverse",
+								Boolean:     true,
+								Description: "Show packages depending on NAME"},
+							CoreFlag{
+								Name:        "tree",
+								Boolean:     true,
+								Description: "Render the full dependency tree"},
+							CoreFlag{
+								Name:        "dot",
+								Boolean:     true,
+								Description: "Render the full dependency graph as Graphviz DOT"},
+							CoreFlag{
+								Name:        "shlibs",
+								Boolean:     true,
+								Description: "Include shared library dependencies"},
+							CoreFlag{
+								Name:        "why",
+								Boolean:     true,
+								Description: "Show why NAME is installed"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg diff": func() (cli.Command, error) {
+			return &PkgDiffCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg diff A [B]",
+						SynopsisText: "Compare package manifests (or a manifest with installed packages)",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg export": func() (cli.Command, error) {
+			return &PkgExportCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg export",
+						SynopsisText: "Export manually installed packages as a manifest",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "output",
+								Boolean:     false,
+								Description: "Write manifest to file"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg hold": func() (cli.Command, error) {
+			return &PkgHoldCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg hold NAME [NAME...]",
+						SynopsisText: "Hold packages at their current version",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "reason",
+								Boolean:     false,
+								Description: "Reason for holding the package"},
+							CoreFlag{
+								Name:        "repolock",
+								Boolean:     true,
+								Description: "Also lock packages to their current repository"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg holds": func() (cli.Command, error) {
+			return &PkgHoldsCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg holds",
+						SynopsisText: "List held packages",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg import": func() (cli.Command, error) {
+			return &PkgImportCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg import FILE",
+						SynopsisText: "Install packages missing from a manifest",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "remove-extras",
+								Boolean:     true,
+								Description: "Remove manually installed packages not in the manifest"},
+							CoreFlag{
+								Name:        "yes",
+								Boolean:     true,
+								Description: "Do not ask for confirmation"},
+							CoreFlag{
+								Name:        "dry-run",
+								Boolean:     true,
+								Description: "Only display the changes"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg info": func() (cli.Command, error) {
+			return &PkgInfoCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg info NAME",
+						SynopsisText: "Display information about an installed package",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg install": func() (cli.Command, error) {
+			return &PkgInstallCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg install NAME [NAME...]",
+						SynopsisText: "Install packages",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "sync",
+								Boolean:     true,
+								Description: "Synchronize repository indexes first"},
+							CoreFlag{
+								Name:        "yes",
+								Boolean:     true,
+								Description: "Do not ask for confirmation"},
+							CoreFlag{
+								Name:        "dry-run",
+								Boolean:     true,
+								Description: "Only display the transaction"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg list": func() (cli.Command, error) {
+			return &PkgListCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg list",
+						SynopsisText: "List installed packages",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "manual",
+								Boolean:     true,
+								Description: "Display manually installed packages"},
+							CoreFlag{
+								Name:        "automatic",
+								Boolean:     true,
+								Description: "Display automatically installed packages"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg needs-restart": func() (cli.Command, error) {
+			return &PkgNeedsRestartCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg needs-restart",
+						SynopsisText: "List services using replaced binaries or libraries",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "restart",
+								Boolean:     true,
+								Description: "Restart affected services"},
+							CoreFlag{
+								Name:        "yes",
+								Boolean:     true,
+								Description: "Restart without confirmation"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg owns": func() (cli.Command, error) {
+			return &PkgOwnsCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg owns PATH",
+						SynopsisText: "Find the installed package owning a file",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg provides": func() (cli.Command, error) {
+			return &PkgProvidesCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg provides COMMAND",
+						SynopsisText: "Find packages providing a command",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "installed",
+								Boolean:     true,
+								Description: "Only search installed packages"},
+							CoreFlag{
+								Name:        "remote",
+								Boolean:     true,
+								Description: "Query remote packages when no file index is available"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg reconfigure": func() (cli.Command, error) {
+			return &PkgReconfigureCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg reconfigure [NAME...|--all-unpacked]",
+						SynopsisText: "List and reconfigure unpacked packages",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "all-unpacked",
+								Boolean:     true,
+								Description: "Reconfigure all packages in the unpacked state"},
+							CoreFlag{
+								Name:        "force",
+								Boolean:     true,
+								Description: "Reconfigure packages which are already configured"},
+							CoreFlag{
+								Name:        "log-dir",
+								Description: "Directory for per-package script logs"},
+							CoreFlag{
+								Name:        "verbose",
+								Boolean:     true,
+								Description: "Show script output of successful packages"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg remove": func() (cli.Command, error) {
+			return &PkgRemoveCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg remove NAME [NAME...]",
+						SynopsisText: "Remove packages",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "recursive",
+								Boolean:     true,
+								Description: "Also remove dependencies no longer required"},
+							CoreFlag{
+								Name:        "yes",
+								Boolean:     true,
+								Description: "Do not ask for confirmation"},
+							CoreFlag{
+								Name:        "dry-run",
+								Boolean:     true,
+								Description: "Only display the transaction"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg search": func() (cli.Command, error) {
+			return &PkgSearchCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg search TERM",
+						SynopsisText: "Search repository indexes for packages",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "fuzzy",
+								Boolean:     true,
+								Description: "Use fuzzy matching instead of a regular expression"},
+							CoreFlag{
+								Name:        "installed",
+								Boolean:     true,
+								Description: "Only display installed packages"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg size": func() (cli.Command, error) {
+			return &PkgSizeCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg size [NAME...]",
+						SynopsisText: "Rank installed packages by size",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "exclusive",
+								Boolean:     true,
+								Description: "Include dependencies freed by removing the package"},
+							CoreFlag{
+								Name:        "limit",
+								Description: "Show only the largest N packages"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg unhold": func() (cli.Command, error) {
+			return &PkgUnholdCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg unhold NAME [NAME...]",
+						SynopsisText: "Release held packages",
+						Flags:        c.flags(),
+						UI:           ui,
+						AppName:      appName,
+					},
+				},
+			}, nil
+		},
+		"pkg update": func() (cli.Command, error) {
+			return &PkgUpdateCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg update [NAME...]",
+						SynopsisText: "Update packages (all by default)",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "sync",
+								Boolean:     true,
+								Description: "Synchronize repository indexes first"},
+							CoreFlag{
+								Name:        "yes",
+								Boolean:     true,
+								Description: "Do not ask for confirmation"},
+							CoreFlag{
+								Name:        "dry-run",
+								Boolean:     true,
+								Description: "Only display the transaction"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"pkg verify": func() (cli.Command, error) {
+			return &PkgVerifyCommand{
+				PkgCommand: PkgCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void pkg verify [NAME...]",
+						SynopsisText: "Verify installed files against package metadata",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "strict",
+								Boolean:     true,
+								Description: "Fail when file modes cannot be verified"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+	}
+}
+
+// Flags shared by all package commands
+func (c *PkgCommand) flags(extra ...CoreFlag) []CoreFlag {
+	return append([]CoreFlag{rootDirFlag()}, extra...)
+}
+
+func (c *PkgCommand) Init(args []string, pkgName bool) (ParsedCli, error) {
+	fmtOpts, err := c.Parse(args)
+	if err != nil {
+		return fmtOpts, err
+	}
+	c.RootDir = fmtOpts.RootDir()
+	if pkgName {
+		if len(fmtOpts.Args) != 1 {
+			return fmtOpts, errors.New("Single package name required!")
+		} else {
+			c.PkgName = fmtOpts.Args[0]
+		}
+	}
+	return fmtOpts, nil
+}
+
+func (c *PkgCommand) PkgDB() (*PkgDB, error) {
+	c.debug(fmt.Sprintf("Loading package database from root `%s`", c.RootDir))
+	db, err := LoadPkgDB(c.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range db.warnings {
+		c.debug(warning)
+	}
+	return db, nil
+}
+
+// Formats a byte count for display
+func humanSize(size int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(size)
+	idx := 0
+	for (value >= 1024 || value <= -1024) && idx < len(units)-1 {
+		value = value / 1024
+		idx++
+	}
+	if idx == 0 {
+		return fmt.Sprintf("%d%s", size, units[idx])
+	}
+	return fmt.Sprintf("%.2f%s", value, units[idx])
+}
@@ -0,0 +1,161 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type PkgChangedConfigsCommand struct {
+	PkgCommand
+}
+
+// Configuration file differing from its packaged state
+type ChangedConfig struct {
+	Package string   `json:"package"`
+	File    string   `json:"file"`
+	Status  []string `json:"status"`
+	Pending []string `json:"pending,omitempty"`
+	Diff    string   `json:"diff,omitempty"`
+}
+
+func (c *PkgChangedConfigsCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	names := cOpts.Args
+	if len(names) == 0 {
+		names = db.Names()
+	}
+	for _, name := range names {
+		if _, ok := db.Packages[name]; !ok {
+			c.UI.Error(fmt.Sprintf(
+				"Package `%s` is not installed!", name))
+			return exitCode
+		}
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	showDiff := cOpts.Get("diff") != nil
+	changed := []*ChangedConfig{}
+	for _, name := range names {
+		pkgChanged := []*ChangedConfig{}
+		for _, confFile := range db.Packages[name].ConfFiles {
+			if result := c.checkConfig(name, confFile); result != nil {
+				pkgChanged = append(pkgChanged, result)
+			}
+		}
+		if showDiff && len(pkgChanged) > 0 {
+			c.addDiffs(db.Packages[name], config.CacheDir(), pkgChanged)
+		}
+		changed = append(changed, pkgChanged...)
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(changed); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	if len(changed) == 0 {
+		c.UI.Info("No configuration files have been changed.")
+		return 0
+	}
+	rows := [][]string{}
+	for _, result := range changed {
+		rows = append(rows, []string{strings.Join(result.Status, ","), result.File, result.Package})
+	}
+	c.outputTable([]string{"STATUS", "FILE", "PACKAGE"}, rows)
+	for _, result := range changed {
+		if result.Diff != "" {
+			c.UI.Output("")
+			c.UI.Output(result.Diff)
+		}
+	}
+	return 0
+}
+
+// Compares a configuration file with the recorded digest and looks
+// for pending `.new-<version>` files left by xbps
+func (c *PkgChangedConfigsCommand) checkConfig(name string, confFile PkgFile) *ChangedConfig {
+	result := &ChangedConfig{Package: name, File: confFile.Path, Status: []string{}}
+	path := filepath.Join(c.RootDir, confFile.Path)
+	digest, err := fileSHA256(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			result.Status = append(result.Status, "missing")
+		} else {
+			c.debug(fmt.Sprintf("Failed to hash `%s`: %s", path, err))
+			result.Status = append(result.Status, "unreadable")
+		}
+	} else if confFile.SHA256 != "" && digest != confFile.SHA256 {
+		result.Status = append(result.Status, "modified")
+	}
+	// Listed rather than globbed as paths may contain glob characters
+	entries, _ := ioutil.ReadDir(filepath.Dir(path))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), filepath.Base(path)+".new-") {
+			result.Pending = append(result.Pending, filepath.Join(filepath.Dir(confFile.Path), entry.Name()))
+		}
+	}
+	if len(result.Pending) > 0 {
+		result.Status = append(result.Status, "pending")
+	}
+	if len(result.Status) == 0 {
+		return nil
+	}
+	return result
+}
+
+// Diffs modified files against the copies in the cached package
+// archive, when the archive is available
+func (c *PkgChangedConfigsCommand) addDiffs(pkg *Package, cacheDir string, changed []*ChangedConfig) {
+	archives, _ := filepath.Glob(filepath.Join(cacheDir, pkg.PkgVer+".*.xbps"))
+	if len(archives) == 0 {
+		c.debug(fmt.Sprintf("No cached archive for `%s`, skipping diff", pkg.PkgVer))
+		return
+	}
+	wanted := map[string]bool{}
+	for _, result := range changed {
+		wanted[result.File] = true
+	}
+	members, err := c.readArchive(archives[0], func(name string) bool {
+		return wanted[archiveMemberPath(name)]
+	})
+	if err != nil {
+		c.UI.Warn(fmt.Sprintf(
+			"Failed to read cached package `%s`: %s", archives[0], err))
+		return
+	}
+	for name, content := range members {
+		file := archiveMemberPath(name)
+		for _, result := range changed {
+			if result.File != file {
+				continue
+			}
+			local, err := ioutil.ReadFile(filepath.Join(c.RootDir, file))
+			if err != nil {
+				continue
+			}
+			result.Diff = unifiedDiff(
+				pkg.PkgVer+":"+file, file, string(content), string(local))
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type PkgCleanCommand struct {
+	PkgCommand
+}
+
+// Package archive (and signatures) found in the xbps cache
+type CachedPkg struct {
+	Name    string
+	Version string
+	Files   []string
+	Size    int64
+}
+
+func (c *PkgCleanCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	dryRun := cOpts.Get("dry-run") != nil
+	if !dryRun && !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	doOrphans := cOpts.Get("cache") == nil || cOpts.Get("orphans") != nil
+	doCache := cOpts.Get("orphans") == nil || cOpts.Get("cache") != nil
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	orphans := []string{}
+	var orphanSize int64
+	if doOrphans {
+		orphans = db.Orphans()
+		rows := [][]string{}
+		for _, name := range orphans {
+			pkg := db.Packages[name]
+			orphanSize = orphanSize + pkg.InstalledSize
+			rows = append(rows, []string{pkg.Name, pkg.Version, humanSize(pkg.InstalledSize)})
+		}
+		if len(rows) > 0 {
+			c.outputTable([]string{"ORPHAN", "VERSION", "SIZE"}, rows)
+			c.UI.Output("")
+		}
+	}
+	obsolete := []*CachedPkg{}
+	var cacheSize int64
+	if doCache {
+		obsolete, err = c.obsoleteCache(db)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to inspect package cache: %s", err))
+			return exitCode
+		}
+		rows := [][]string{}
+		for _, cached := range obsolete {
+			cacheSize = cacheSize + cached.Size
+			rows = append(rows, []string{cached.Name, cached.Version, humanSize(cached.Size)})
+		}
+		if len(rows) > 0 {
+			c.outputTable([]string{"CACHED", "VERSION", "SIZE"}, rows)
+			c.UI.Output("")
+		}
+	}
+	if len(orphans) == 0 && len(obsolete) == 0 {
+		c.UI.Info("Nothing to clean.")
+		return 0
+	}
+	c.UI.Output(fmt.Sprintf("Orphaned packages:    %d (%s)", len(orphans), humanSize(orphanSize)))
+	c.UI.Output(fmt.Sprintf("Obsolete cache files: %d (%s)", len(obsolete), humanSize(cacheSize)))
+	c.UI.Output(fmt.Sprintf("Total reclaimable:    %s", humanSize(orphanSize+cacheSize)))
+	if dryRun {
+		return 0
+	}
+	if cOpts.Get("yes") == nil {
+		ok, err := c.confirm("Remove these packages and files?")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to read confirmation: %s", err))
+			return exitCode
+		}
+		if !ok {
+			c.UI.Warn("Clean aborted.")
+			return exitCode
+		}
+	}
+	var freed int64
+	if len(orphans) > 0 {
+		xArgs := append(append(c.xbpsRootArgs(), "-y"), orphans...)
+		if c.ExecuteCommand(exec.Command(XBPS_REMOVE_PATH, xArgs...)) != 0 {
+			c.UI.Error("Failed to remove orphaned packages!")
+			return exitCode
+		}
+		for _, name := range orphans {
+			c.UI.Info(fmt.Sprintf(
+				"Removed package: %s", db.Packages[name].PkgVer))
+		}
+		freed = freed + orphanSize
+	}
+	for _, cached := range obsolete {
+		for _, path := range cached.Files {
+			if err := os.Remove(path); err != nil {
+				c.UI.Error(fmt.Sprintf(
+					"Failed to remove cached file: %s", err))
+				return exitCode
+			}
+		}
+		freed = freed + cached.Size
+	}
+	if len(obsolete) > 0 {
+		c.UI.Info(fmt.Sprintf(
+			"Removed %d obsolete cached packages", len(obsolete)))
+	}
+	c.UI.Info(fmt.Sprintf("Freed %s", humanSize(freed)))
+	return 0
+}
+
+// Cached package archives matching neither the installed version nor
+// a version available from the configured repositories.
+func (c *PkgCleanCommand) obsoleteCache(db *PkgDB) ([]*CachedPkg, error) {
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := c.cachedPackages(config.CacheDir())
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]bool{}
+	for _, pkg := range db.Packages {
+		current[pkg.PkgVer] = true
+	}
+	// Without repository indexes newer cached versions cannot be told
+	// apart from obsolete ones
+	indexes, err := c.RepoIndexes()
+	if err == nil && len(indexes) == 0 {
+		err = errors.New("no repository data found")
+	}
+	if err != nil {
+		c.UI.Warn(fmt.Sprintf(
+			"Repository indexes unavailable, skipping package cache: %s", err))
+		return []*CachedPkg{}, nil
+	}
+	for _, index := range indexes {
+		for _, pkg := range index.Packages {
+			current[pkg.PkgVer] = true
+		}
+	}
+	obsolete := []*CachedPkg{}
+	for _, pkg := range cached {
+		if !current[pkg.Name+"-"+pkg.Version] {
+			obsolete = append(obsolete, pkg)
+		}
+	}
+	return obsolete, nil
+}
+
+// Groups cache directory contents (`pkgver.arch.xbps` and signature
+// files) by package version.
+func (c *PkgCleanCommand) cachedPackages(cacheDir string) ([]*CachedPkg, error) {
+	c.debug(fmt.Sprintf("Reading package cache `%s`", cacheDir))
+	matches, err := filepath.Glob(filepath.Join(cacheDir, "*.xbps*"))
+	if err != nil {
+		return nil, err
+	}
+	cached := []*CachedPkg{}
+	byPkgVer := map[string]*CachedPkg{}
+	for _, path := range matches {
+		base := filepath.Base(path)
+		idx := strings.LastIndex(base, ".xbps")
+		if idx == -1 || (base[idx:] != ".xbps" && !strings.HasPrefix(base[idx:], ".xbps.sig")) {
+			continue
+		}
+		base = base[:idx]
+		if dot := strings.LastIndex(base, "."); dot != -1 {
+			base = base[:dot]
+		}
+		name, version, err := splitPkgVer(base)
+		if err != nil {
+			c.debug(fmt.Sprintf("Skipping unknown cache file `%s`", path))
+			continue
+		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+		pkg, ok := byPkgVer[base]
+		if !ok {
+			pkg = &CachedPkg{Name: name, Version: version}
+			byPkgVer[base] = pkg
+			cached = append(cached, pkg)
+		}
+		pkg.Files = append(pkg.Files, path)
+		pkg.Size = pkg.Size + info.Size()
+	}
+	return cached, nil
+}
@@ -0,0 +1,421 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type PkgDepsCommand struct {
+	PkgCommand
+	db       *PkgDB
+	indexes  []*RepoIndex
+	shlibs   bool
+	reverse  bool
+	shlibMap map[string][]string
+	revDeps  map[string][]string
+}
+
+// Dependency relation between two packages
+type DepEdge struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Type       string `json:"type"`
+	Pattern    string `json:"pattern"`
+	Constraint string `json:"constraint"`
+	Version    string `json:"version,omitempty"`
+	Status     string `json:"status"`
+}
+
+func (c *PkgDepsCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, true)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if cOpts.Get("tree") != nil && cOpts.Get("dot") != nil {
+		c.UI.Error("Only one of `--tree` or `--dot` may be used!")
+		return exitCode
+	}
+	c.reverse = cOpts.Get("reverse") != nil
+	c.shlibs = cOpts.Get("shlibs") != nil
+	if c.db, err = c.PkgDB(); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	if c.indexes, err = c.RepoIndexes(); err != nil {
+		c.debug(fmt.Sprintf(
+			"Resolving dependencies without repository indexes: %s", err))
+	}
+	pkg, installed := c.lookup(c.PkgName)
+	if pkg == nil {
+		c.UI.Error(fmt.Sprintf(
+			"Package `%s` is not installed or available!", c.PkgName))
+		return exitCode
+	}
+	c.PkgName = pkg.Name
+	if c.reverse && !installed {
+		c.UI.Error(fmt.Sprintf(
+			"Package `%s` is not installed!", c.PkgName))
+		return exitCode
+	}
+	if cOpts.Get("why") != nil {
+		return c.why(cOpts.Get("json") != nil)
+	}
+	next := c.dependencies
+	if c.reverse {
+		next = c.dependents
+	}
+	edges := next(c.PkgName)
+	if cOpts.Get("tree") != nil || cOpts.Get("dot") != nil {
+		edges = c.walk(c.PkgName, next)
+	}
+	switch {
+	case cOpts.Get("json") != nil:
+		if err := c.outputJSON(edges); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+	case cOpts.Get("dot") != nil:
+		c.UI.Output(c.dot(edges))
+	case cOpts.Get("tree") != nil:
+		c.UI.Output(c.PkgName)
+		c.tree(c.PkgName, edges, "", map[string]bool{c.PkgName: true})
+	default:
+		if len(edges) == 0 {
+			c.UI.Info("No dependencies found")
+			return 0
+		}
+		rows := [][]string{}
+		for _, edge := range edges {
+			rows = append(rows, []string{c.other(edge), edge.Type, edge.Constraint, edge.Version, edge.Status})
+		}
+		c.outputTable([]string{"PACKAGE", "TYPE", "CONSTRAINT", "VERSION", "STATUS"}, rows)
+	}
+	return 0
+}
+
+// Finds a package in the pkgdb, falling back to the repository indexes.
+// Virtual package names are resolved to their provider.
+func (c *PkgDepsCommand) lookup(pattern string) (*Package, bool) {
+	if name, ok := c.db.Resolve(pattern); ok {
+		return c.db.Packages[name], true
+	}
+	name := depPatternName(pattern)
+	for _, index := range c.indexes {
+		if pkg, ok := index.Packages[name]; ok {
+			return pkg, false
+		}
+	}
+	for _, index := range c.indexes {
+		for _, pkgName := range sortedPackageNames(index.Packages) {
+			pkg := index.Packages[pkgName]
+			for _, provided := range pkg.Provides {
+				if depPatternName(provided) == name {
+					return pkg, false
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// Direct dependencies of a package
+func (c *PkgDepsCommand) dependencies(name string) []DepEdge {
+	pkg, _ := c.lookup(name)
+	if pkg == nil {
+		return []DepEdge{}
+	}
+	edges := []DepEdge{}
+	for _, pattern := range pkg.RunDepends {
+		dep := parseDepPattern(pattern)
+		edge := DepEdge{
+			From:       name,
+			To:         dep.Name,
+			Type:       "run",
+			Pattern:    pattern,
+			Constraint: dep.Constraint()}
+		target, installed := c.lookup(pattern)
+		switch {
+		case target == nil:
+			edge.Status = "missing"
+		case !installed:
+			edge.To = target.Name
+			edge.Version = target.Version
+			edge.Status = "available"
+		default:
+			edge.To = target.Name
+			edge.Version = target.Version
+			edge.Status = "ok"
+			// Virtual dependencies are satisfied by any provider
+			if target.Name == dep.Name && !dep.Match(target.Version) {
+				edge.Status = "unsatisfied"
+			}
+		}
+		edges = append(edges, edge)
+	}
+	if c.shlibs {
+		for _, shlib := range pkg.ShlibRequires {
+			edge := DepEdge{
+				From:       name,
+				Type:       "shlib",
+				Pattern:    shlib,
+				Constraint: shlib,
+				Status:     "missing"}
+			if providers := c.shlibProviders()[shlib]; len(providers) > 0 {
+				edge.To = providers[0]
+				edge.Version = c.db.Packages[providers[0]].Version
+				edge.Status = "ok"
+			}
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// Reverse dependencies of the package database, computed once
+func (c *PkgDepsCommand) reverseDepends() map[string][]string {
+	if c.revDeps == nil {
+		c.revDeps = c.db.ReverseDepends()
+	}
+	return c.revDeps
+}
+
+// Installed packages depending on a package
+func (c *PkgDepsCommand) dependents(name string) []DepEdge {
+	edges := []DepEdge{}
+	target := c.db.Packages[name]
+	for _, revDep := range c.reverseDepends()[name] {
+		for _, pattern := range c.db.Packages[revDep].RunDepends {
+			if resolved, ok := c.db.Resolve(pattern); !ok || resolved != name {
+				continue
+			}
+			dep := parseDepPattern(pattern)
+			status := "ok"
+			if dep.Name == name && !dep.Match(target.Version) {
+				status = "unsatisfied"
+			}
+			edges = append(edges, DepEdge{
+				From:       revDep,
+				To:         name,
+				Type:       "run",
+				Pattern:    pattern,
+				Constraint: dep.Constraint(),
+				Version:    c.db.Packages[revDep].Version,
+				Status:     status})
+			break
+		}
+	}
+	if c.shlibs {
+		provided := map[string]bool{}
+		for _, shlib := range target.ShlibProvides {
+			provided[shlib] = true
+		}
+		for _, pkgName := range c.db.Names() {
+			if pkgName == name {
+				continue
+			}
+			for _, shlib := range c.db.Packages[pkgName].ShlibRequires {
+				if provided[shlib] {
+					edges = append(edges, DepEdge{
+						From:       pkgName,
+						To:         name,
+						Type:       "shlib",
+						Pattern:    shlib,
+						Constraint: shlib,
+						Version:    c.db.Packages[pkgName].Version,
+						Status:     "ok"})
+				}
+			}
+		}
+	}
+	return edges
+}
+
+// Maps shared library sonames to the installed packages providing them
+func (c *PkgDepsCommand) shlibProviders() map[string][]string {
+	if c.shlibMap == nil {
+		c.shlibMap = map[string][]string{}
+		for _, name := range c.db.Names() {
+			for _, shlib := range c.db.Packages[name].ShlibProvides {
+				c.shlibMap[shlib] = append(c.shlibMap[shlib], name)
+			}
+		}
+	}
+	return c.shlibMap
+}
+
+// Collects all edges reachable from the given package
+func (c *PkgDepsCommand) walk(name string, next func(string) []DepEdge) []DepEdge {
+	edges := []DepEdge{}
+	seen := map[string]bool{name: true}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range next(current) {
+			edges = append(edges, edge)
+			other := c.other(edge)
+			if other != "" && !seen[other] {
+				seen[other] = true
+				queue = append(queue, other)
+			}
+		}
+	}
+	return edges
+}
+
+// Renders the edges below a package as an ASCII tree. Packages already
+// expanded elsewhere in the tree are marked with `(*)`.
+func (c *PkgDepsCommand) tree(name string, edges []DepEdge, prefix string, expanded map[string]bool) {
+	children := []DepEdge{}
+	for _, edge := range edges {
+		if (c.reverse && edge.To == name) || (!c.reverse && edge.From == name) {
+			children = append(children, edge)
+		}
+	}
+	for i, edge := range children {
+		child := c.other(edge)
+		branch, indent := "|-- ", "|   "
+		if i == len(children)-1 {
+			branch, indent = "`-- ", "    "
+		}
+		label := child
+		if child == "" {
+			label = edge.Pattern
+		} else if edge.Type == "shlib" {
+			label = fmt.Sprintf("%s [%s]", child, edge.Pattern)
+		} else if edge.Constraint != "*" {
+			label = fmt.Sprintf("%s (%s)", child, edge.Constraint)
+		}
+		if edge.Status != "ok" {
+			label = label + " " + edge.Status
+		}
+		if child != "" && expanded[child] {
+			c.UI.Output(prefix + branch + label + " (*)")
+			continue
+		}
+		c.UI.Output(prefix + branch + label)
+		if child != "" {
+			expanded[child] = true
+			c.tree(child, edges, prefix+indent, expanded)
+		}
+	}
+}
+
+// Package on the far side of an edge from the walk direction
+func (c *PkgDepsCommand) other(edge DepEdge) string {
+	if c.reverse {
+		return edge.From
+	}
+	return edge.To
+}
+
+// Renders edges as a Graphviz digraph
+func (c *PkgDepsCommand) dot(edges []DepEdge) string {
+	lines := []string{"digraph \"" + c.PkgName + "\" {",
+		"  node [shape=box];",
+		fmt.Sprintf("  %q [style=bold];", c.PkgName)}
+	for _, edge := range edges {
+		to := edge.To
+		if to == "" {
+			to = edge.Pattern
+		}
+		attrs := []string{}
+		if edge.Type == "shlib" {
+			attrs = append(attrs, "style=dashed", fmt.Sprintf("label=%q", edge.Pattern))
+		} else if edge.Constraint != "*" {
+			attrs = append(attrs, fmt.Sprintf("label=%q", edge.Constraint))
+		}
+		if edge.Status != "ok" {
+			attrs = append(attrs, "color=red")
+		}
+		line := fmt.Sprintf("  %q -> %q", edge.From, to)
+		if len(attrs) > 0 {
+			line = line + " [" + strings.Join(attrs, ", ") + "]"
+		}
+		lines = append(lines, line+";")
+	}
+	return strings.Join(append(lines, "}"), "\n")
+}
+
+// Explains why a package is installed by tracing the shortest chain of
+// reverse dependencies to a manually installed package
+func (c *PkgDepsCommand) why(asJSON bool) int {
+	pkg, installed := c.db.Packages[c.PkgName]
+	if !installed {
+		c.UI.Error(fmt.Sprintf(
+			"Package `%s` is not installed!", c.PkgName))
+		return 1
+	}
+	chain, err := c.installChain(pkg)
+	if asJSON {
+		result := map[string]interface{}{
+			"package":   c.PkgName,
+			"automatic": pkg.Automatic,
+			"chain":     chain}
+		if err := c.outputJSON(result); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return 1
+		}
+		return 0
+	}
+	if err != nil {
+		c.UI.Warn(err.Error())
+		return 0
+	}
+	if len(chain) == 1 {
+		c.UI.Info(fmt.Sprintf("Package `%s` was installed manually", c.PkgName))
+		return 0
+	}
+	c.UI.Info(fmt.Sprintf("Package `%s` is required by manually installed package `%s`:",
+		c.PkgName, chain[0]))
+	c.UI.Output("  " + strings.Join(chain, " -> "))
+	return 0
+}
+
+func (c *PkgDepsCommand) installChain(pkg *Package) ([]string, error) {
+	if !pkg.Automatic {
+		return []string{pkg.Name}, nil
+	}
+	revDeps := c.reverseDepends()
+	parent := map[string]string{pkg.Name: ""}
+	queue := []string{pkg.Name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		dependents := append([]string{}, revDeps[current]...)
+		sort.Strings(dependents)
+		for _, revDep := range dependents {
+			if _, ok := parent[revDep]; ok {
+				continue
+			}
+			parent[revDep] = current
+			if !c.db.Packages[revDep].Automatic {
+				chain := []string{}
+				for name := revDep; name != ""; name = parent[name] {
+					chain = append(chain, name)
+				}
+				return chain, nil
+			}
+			queue = append(queue, revDep)
+		}
+	}
+	return []string{}, errors.New(fmt.Sprintf(
+		"Package `%s` is not required by any manually installed package (orphan)", pkg.Name))
+}
+
+func sortedPackageNames(packages map[string]*Package) []string {
+	names := []string{}
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,147 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+)
+
+type PkgDiffCommand struct {
+	PkgCommand
+}
+
+// Difference between two package manifests
+type ManifestChange struct {
+	Name   string `json:"name"`
+	Change string `json:"change"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+func (c *PkgDiffCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) < 1 || len(cOpts.Args) > 2 {
+		c.UI.Error("One or two manifest files required!")
+		return exitCode
+	}
+	from, err := LoadManifest(cOpts.Args[0])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load manifest: %s", err))
+		return exitCode
+	}
+	var to *PkgManifest
+	if len(cOpts.Args) == 2 {
+		if to, err = LoadManifest(cOpts.Args[1]); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to load manifest: %s", err))
+			return exitCode
+		}
+	} else {
+		if to, err = c.liveManifest(); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to build manifest of installed packages: %s", err))
+			return exitCode
+		}
+	}
+	changes := c.diffManifests(from, to)
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(changes); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	if len(changes) == 0 {
+		c.UI.Info("Manifests are identical.")
+		return 0
+	}
+	rows := [][]string{}
+	for _, change := range changes {
+		mark := "~"
+		switch change.Change {
+		case "added", "repository added":
+			mark = "+"
+		case "removed", "repository removed":
+			mark = "-"
+		}
+		detail := change.From
+		if change.To != "" {
+			if detail != "" {
+				detail = detail + " -> "
+			}
+			detail = detail + change.To
+		}
+		rows = append(rows, []string{mark, change.Name, change.Change, detail})
+	}
+	c.outputTable(nil, rows)
+	return 0
+}
+
+func (c *PkgDiffCommand) liveManifest() (*PkgManifest, error) {
+	db, err := c.PkgDB()
+	if err != nil {
+		return nil, err
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := LoadHoldNotes(c.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewManifest(db, config, notes), nil
+}
+
+// Lists packages added, removed or changed (version, hold or repolock)
+// going from one manifest to the other, ordered by name
+func (c *PkgDiffCommand) diffManifests(from *PkgManifest, to *PkgManifest) []ManifestChange {
+	changes := []ManifestChange{}
+	fromPkgs := from.PackageMap()
+	toPkgs := to.PackageMap()
+	for name, pkg := range fromPkgs {
+		other, ok := toPkgs[name]
+		if !ok {
+			changes = append(changes, ManifestChange{Name: name, Change: "removed", From: pkg.Version})
+			continue
+		}
+		if pkg.Version != "" && other.Version != "" && pkg.Version != other.Version {
+			changes = append(changes, ManifestChange{
+				Name: name, Change: "version", From: pkg.Version, To: other.Version})
+		}
+		if pkg.Hold != other.Hold {
+			changes = append(changes, ManifestChange{
+				Name: name, Change: "hold", From: fmt.Sprintf("%t", pkg.Hold), To: fmt.Sprintf("%t", other.Hold)})
+		}
+		if pkg.RepoLock != other.RepoLock {
+			changes = append(changes, ManifestChange{
+				Name: name, Change: "repolock", From: fmt.Sprintf("%t", pkg.RepoLock), To: fmt.Sprintf("%t", other.RepoLock)})
+		}
+	}
+	for name, pkg := range toPkgs {
+		if _, ok := fromPkgs[name]; !ok {
+			changes = append(changes, ManifestChange{Name: name, Change: "added", To: pkg.Version})
+		}
+	}
+	for _, repo := range to.Repositories {
+		if !c.contains(from.Repositories, repo) {
+			changes = append(changes, ManifestChange{Name: repo, Change: "repository added"})
+		}
+	}
+	for _, repo := range from.Repositories {
+		if !c.contains(to.Repositories, repo) {
+			changes = append(changes, ManifestChange{Name: repo, Change: "repository removed"})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
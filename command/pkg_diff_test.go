@@ -0,0 +1,36 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffManifests(t *testing.T) {
+	from := &PkgManifest{
+		Repositories: []string{"https://repo-default.voidlinux.org/current"},
+		Packages: []ManifestPkg{
+			{Name: "foo", Version: "1.0_1", Hold: true},
+			{Name: "libbar", Version: "1.2_1"},
+			{Name: "vim", Version: "9.0_1"}}}
+	to := &PkgManifest{
+		Repositories: []string{"https://repo-fastly.voidlinux.org/current"},
+		Packages: []ManifestPkg{
+			{Name: "foo", Version: "1.1_1"},
+			{Name: "libbar", Version: "1.2_1", RepoLock: true},
+			{Name: "zsh", Version: "5.9_1"}}}
+	c := &PkgDiffCommand{}
+	expected := []ManifestChange{
+		{Name: "foo", Change: "version", From: "1.0_1", To: "1.1_1"},
+		{Name: "foo", Change: "hold", From: "true", To: "false"},
+		{Name: "https://repo-default.voidlinux.org/current", Change: "repository removed"},
+		{Name: "https://repo-fastly.voidlinux.org/current", Change: "repository added"},
+		{Name: "libbar", Change: "repolock", From: "false", To: "true"},
+		{Name: "vim", Change: "removed", From: "9.0_1"},
+		{Name: "zsh", Change: "added", To: "5.9_1"}}
+	if changes := c.diffManifests(from, to); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("diffManifests() = %+v, expected %+v", changes, expected)
+	}
+	if changes := c.diffManifests(to, to); len(changes) != 0 {
+		t.Errorf("diffManifests() of identical manifests = %+v", changes)
+	}
+}
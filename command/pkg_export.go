@@ -0,0 +1,54 @@
+package command
+
+import (
+	"fmt"
+)
+
+type PkgExportCommand struct {
+	PkgCommand
+}
+
+func (c *PkgExportCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	notes, err := LoadHoldNotes(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load hold notes: %s", err))
+		return exitCode
+	}
+	manifest := NewManifest(db, config, notes)
+	if flag := cOpts.Get("output"); flag != nil && flag.Value != "" {
+		if err := manifest.Save(flag.Value); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to write manifest: %s", err))
+			return exitCode
+		}
+		c.UI.Info(fmt.Sprintf(
+			"Exported %d packages to %s", len(manifest.Packages), flag.Value))
+		return 0
+	}
+	if err := c.outputJSON(manifest); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to generate JSON output: %s", err))
+		return exitCode
+	}
+	return 0
+}
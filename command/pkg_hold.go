@@ -0,0 +1,84 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+type PkgHoldCommand struct {
+	PkgCommand
+}
+
+func (c *PkgHoldCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) == 0 {
+		c.UI.Error("At least one package name required!")
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	notes, err := LoadHoldNotes(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load hold notes: %s", err))
+		return exitCode
+	}
+	for _, name := range cOpts.Args {
+		if _, ok := db.Packages[name]; !ok {
+			c.UI.Error(fmt.Sprintf(
+				"Package `%s` is not installed!", name))
+			return exitCode
+		}
+	}
+	reason := ""
+	if flag := cOpts.Get("reason"); flag != nil {
+		reason = flag.Value
+	}
+	repoLock := cOpts.Get("repolock") != nil
+	for _, name := range cOpts.Args {
+		pkg := db.Packages[name]
+		if !c.SetPkgMode(name, "hold") {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to hold package `%s`!", name))
+			return exitCode
+		}
+		if repoLock && !c.SetPkgMode(name, "repolock") {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to lock repository of package `%s`!", name))
+			return exitCode
+		}
+		// Holding again keeps the previous reason unless a new one is given
+		note := HoldNote{
+			Version: pkg.Version,
+			Reason:  reason,
+			Date:    time.Now().UTC().Format(time.RFC3339),
+			User:    os.Getenv("SUDO_USER")}
+		if note.Reason == "" {
+			note.Reason = notes[name].Reason
+		}
+		notes[name] = note
+		c.UI.Info(fmt.Sprintf(
+			"Held package: %s", pkg.PkgVer))
+	}
+	if err := notes.Save(c.RootDir); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to save hold notes: %s", err))
+		return exitCode
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type PkgHoldsCommand struct {
+	PkgCommand
+}
+
+// Held package with its recorded hold note
+type PkgHold struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Hold     bool   `json:"hold"`
+	RepoLock bool   `json:"repolock"`
+	HoldNote
+}
+
+func (c *PkgHoldsCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	notes, err := LoadHoldNotes(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load hold notes: %s", err))
+		return exitCode
+	}
+	holds := []PkgHold{}
+	for _, name := range db.Names() {
+		pkg := db.Packages[name]
+		if !pkg.Hold && !pkg.RepoLock {
+			continue
+		}
+		holds = append(holds, PkgHold{
+			Name:     pkg.Name,
+			Version:  pkg.Version,
+			Hold:     pkg.Hold,
+			RepoLock: pkg.RepoLock,
+			HoldNote: notes[name]})
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(holds); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	rows := [][]string{}
+	for _, hold := range holds {
+		flags := []string{}
+		if hold.Hold {
+			flags = append(flags, "hold")
+		}
+		if hold.RepoLock {
+			flags = append(flags, "repolock")
+		}
+		since := hold.Date
+		if since != "" && hold.User != "" {
+			since = since + " by " + hold.User
+		}
+		rows = append(rows, []string{
+			hold.Name, hold.Version, strings.Join(flags, ","), since, hold.Reason})
+	}
+	if len(rows) > 0 {
+		c.outputTable([]string{"NAME", "VERSION", "FLAGS", "SINCE", "REASON"}, rows)
+	} else {
+		c.UI.Info("No packages are held.")
+	}
+	for _, name := range notes.Names() {
+		note := notes[name]
+		pkg, ok := db.Packages[name]
+		if !ok || (!pkg.Hold && !pkg.RepoLock) {
+			c.UI.Warn(fmt.Sprintf(
+				"Hold note for `%s` but package is no longer held", name))
+		} else if pkg.Version != note.Version {
+			c.UI.Warn(fmt.Sprintf(
+				"Package `%s` was held at %s but %s is installed",
+				name, note.Version, pkg.Version))
+		}
+	}
+	return 0
+}
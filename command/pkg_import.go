@@ -0,0 +1,144 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+type PkgImportCommand struct {
+	PkgCommand
+}
+
+func (c *PkgImportCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single manifest file required!")
+		return exitCode
+	}
+	dryRun := cOpts.Get("dry-run") != nil
+	if !dryRun && !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	manifest, err := LoadManifest(cOpts.Args[0])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load manifest: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	configured := []string{}
+	for _, repo := range config.Repositories() {
+		configured = append(configured, repo.Value)
+	}
+	for _, repo := range manifest.Repositories {
+		if !c.contains(configured, repo) {
+			c.UI.Warn(fmt.Sprintf(
+				"Manifest repository is not configured: %s", repo))
+		}
+	}
+	wanted := manifest.PackageMap()
+	missing := []string{}
+	manual := []string{}
+	for _, pkg := range manifest.Packages {
+		installed, ok := db.Packages[pkg.Name]
+		if !ok {
+			missing = append(missing, pkg.Name)
+		} else if installed.Automatic {
+			manual = append(manual, pkg.Name)
+		}
+	}
+	extras := []string{}
+	if cOpts.Get("remove-extras") != nil {
+		for _, name := range db.Names() {
+			if _, ok := wanted[name]; !ok && !db.Packages[name].Automatic {
+				extras = append(extras, name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		if result := c.RunTransaction(XBPS_INSTALL_PATH, missing, cOpts); result != 0 {
+			return result
+		}
+	}
+	if len(extras) > 0 {
+		if result := c.RunTransaction(XBPS_REMOVE_PATH, append([]string{"-R"}, extras...), cOpts); result != 0 {
+			return result
+		}
+	}
+	notes, err := LoadHoldNotes(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load hold notes: %s", err))
+		return exitCode
+	}
+	for _, name := range manual {
+		if dryRun {
+			c.UI.Output(fmt.Sprintf("Would mark package as manually installed: %s", name))
+		} else if !c.SetPkgMode(name, "manual") {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to mark package `%s` as manually installed!", name))
+			return exitCode
+		}
+	}
+	holds := 0
+	for _, pkg := range manifest.Packages {
+		if installed, ok := db.Packages[pkg.Name]; ok && installed.Hold == pkg.Hold && installed.RepoLock == pkg.RepoLock {
+			continue
+		}
+		if !pkg.Hold && !pkg.RepoLock {
+			continue
+		}
+		if dryRun {
+			c.UI.Output(fmt.Sprintf("Would hold package: %s", pkg.Name))
+			continue
+		}
+		if pkg.Hold && !c.SetPkgMode(pkg.Name, "hold") {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to hold package `%s`!", pkg.Name))
+			return exitCode
+		}
+		if pkg.RepoLock && !c.SetPkgMode(pkg.Name, "repolock") {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to lock repository of package `%s`!", pkg.Name))
+			return exitCode
+		}
+		notes[pkg.Name] = HoldNote{
+			Version: pkg.Version,
+			Reason:  pkg.Reason,
+			Date:    time.Now().UTC().Format(time.RFC3339),
+			User:    os.Getenv("SUDO_USER")}
+		holds++
+		c.UI.Info(fmt.Sprintf(
+			"Held package: %s", pkg.Name))
+	}
+	if holds > 0 {
+		if err := notes.Save(c.RootDir); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to save hold notes: %s", err))
+			return exitCode
+		}
+	}
+	if len(missing) == 0 && len(extras) == 0 && len(manual) == 0 && holds == 0 && !dryRun {
+		c.UI.Info("System already matches manifest.")
+	}
+	return 0
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type PkgInfoCommand struct {
+	PkgCommand
+}
+
+func (c *PkgInfoCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, true)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	pkg, ok := db.Packages[c.PkgName]
+	if !ok {
+		c.UI.Error(fmt.Sprintf(
+			"Package `%s` is not installed!", c.PkgName))
+		return exitCode
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(pkg); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	mode := "manual"
+	if pkg.Automatic {
+		mode = "automatic"
+	}
+	rows := [][]string{
+		[]string{"Name:", pkg.Name},
+		[]string{"Version:", pkg.Version},
+		[]string{"Description:", pkg.ShortDesc},
+		[]string{"State:", pkg.State},
+		[]string{"Install mode:", mode},
+		[]string{"Hold:", fmt.Sprintf("%t", pkg.Hold)},
+		[]string{"Repolock:", fmt.Sprintf("%t", pkg.RepoLock)},
+		[]string{"Architecture:", pkg.Architecture},
+		[]string{"Repository:", pkg.Repository},
+		[]string{"Installed size:", humanSize(pkg.InstalledSize)},
+		[]string{"Install date:", pkg.InstallDate},
+		[]string{"Homepage:", pkg.Homepage},
+		[]string{"License:", pkg.License},
+		[]string{"Maintainer:", pkg.Maintainer}}
+	rows = append(rows, c.listRows("Run depends:", pkg.RunDepends)...)
+	rows = append(rows, c.listRows("Shlib provides:", pkg.ShlibProvides)...)
+	rows = append(rows, c.listRows("Shlib requires:", pkg.ShlibRequires)...)
+	groups := []string{}
+	for group := range pkg.Alternatives {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	rows = append(rows, c.listRows("Alternatives:", groups)...)
+	confFiles := []string{}
+	for _, file := range pkg.ConfFiles {
+		confFiles = append(confFiles, strings.TrimSpace(file.Path+" "+file.SHA256))
+	}
+	rows = append(rows, c.listRows("Conf files:", confFiles)...)
+	c.outputTable(nil, rows)
+	return 0
+}
+
+// Builds table rows for a labeled list, one item per row
+func (c *PkgInfoCommand) listRows(label string, items []string) [][]string {
+	rows := [][]string{}
+	for i, item := range items {
+		if i > 0 {
+			label = ""
+		}
+		rows = append(rows, []string{label, item})
+	}
+	return rows
+}
@@ -0,0 +1,25 @@
+package command
+
+import (
+	"fmt"
+)
+
+type PkgInstallCommand struct {
+	PkgCommand
+}
+
+func (c *PkgInstallCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) == 0 {
+		c.UI.Error("At least one package name required!")
+		return exitCode
+	}
+	xArgs := []string{}
+	return c.RunTransaction(XBPS_INSTALL_PATH, append(xArgs, cOpts.Args...), cOpts)
+}
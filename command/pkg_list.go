@@ -0,0 +1,75 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type PkgListCommand struct {
+	PkgCommand
+}
+
+func (c *PkgListCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	notes, err := LoadHoldNotes(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load hold notes: %s", err))
+		return exitCode
+	}
+	pkgs := []*Package{}
+	for _, name := range db.Names() {
+		pkg := db.Packages[name]
+		if pkg.Hold || pkg.RepoLock {
+			pkg.HoldReason = notes[name].Reason
+		}
+		if cOpts.Get("manual") != nil && pkg.Automatic {
+			continue
+		}
+		if cOpts.Get("automatic") != nil && !pkg.Automatic {
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(pkgs); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	rows := [][]string{}
+	for _, pkg := range pkgs {
+		mode := "manual"
+		if pkg.Automatic {
+			mode = "auto"
+		}
+		holds := []string{}
+		if pkg.Hold {
+			holds = append(holds, "hold")
+		}
+		if pkg.RepoLock {
+			holds = append(holds, "repolock")
+		}
+		hold := strings.Join(holds, ",")
+		if pkg.HoldReason != "" {
+			hold = hold + " (" + pkg.HoldReason + ")"
+		}
+		rows = append(rows, []string{pkg.Name, pkg.Version, pkg.State, mode, hold})
+	}
+	c.outputTable([]string{"NAME", "VERSION", "STATE", "MODE", "HOLD"}, rows)
+	return 0
+}
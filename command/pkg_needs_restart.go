@@ -0,0 +1,133 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type PkgNeedsRestartCommand struct {
+	PkgCommand
+}
+
+// Services and processes still using replaced files
+type RestartReport struct {
+	Services  []string        `json:"services"`
+	Processes []*StaleProcess `json:"processes"`
+	Reboot    bool            `json:"reboot"`
+}
+
+func (c *PkgNeedsRestartCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if c.RootDir != "/" {
+		c.UI.Error("Processes can only be inspected on the running system, `--rootdir` is not supported!")
+		return exitCode
+	}
+	restart := cOpts.Get("restart") != nil
+	if restart && !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	procs, err := scanStaleProcesses(PROC_PATH)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to scan processes: %s", err))
+		return exitCode
+	}
+	svc := &ServiceCommand{CoreCommand: c.CoreCommand}
+	services := supervisedPids(svc.enabledServicePath())
+	parents := procParents(PROC_PATH)
+	report := &RestartReport{Services: []string{}, Processes: procs}
+	affected := map[string]bool{}
+	for _, proc := range procs {
+		proc.Service = processService(proc.Pid, parents, services)
+		if proc.Service != "" {
+			affected[proc.Service] = true
+		}
+		if proc.Pid == 1 {
+			report.Reboot = true
+		}
+	}
+	for service := range affected {
+		report.Services = append(report.Services, service)
+	}
+	sort.Strings(report.Services)
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(report); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+	} else {
+		c.showReport(report)
+	}
+	if !restart || len(report.Services) == 0 {
+		return 0
+	}
+	if cOpts.Get("yes") == nil {
+		ok, err := c.confirm(fmt.Sprintf(
+			"Restart %d services?", len(report.Services)))
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to read confirmation: %s", err))
+			return exitCode
+		}
+		if !ok {
+			c.UI.Warn("Restart aborted.")
+			return exitCode
+		}
+	}
+	failed := false
+	for _, service := range report.Services {
+		svc.ServiceName = service
+		if svc.RestartService() {
+			c.UI.Info(fmt.Sprintf("Restarted service: %s", service))
+		} else {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to restart service: %s", service))
+			failed = true
+		}
+	}
+	if failed {
+		return exitCode
+	}
+	return 0
+}
+
+func (c *PkgNeedsRestartCommand) showReport(report *RestartReport) {
+	if !c.isRoot() {
+		c.UI.Warn("Not running as `root`, processes of other users were not inspected")
+	}
+	if len(report.Processes) == 0 {
+		c.UI.Info("No processes are using replaced files")
+		return
+	}
+	rows := [][]string{}
+	for _, proc := range report.Processes {
+		service := proc.Service
+		if service == "" {
+			service = "-"
+		}
+		files := proc.Files[0]
+		if len(proc.Files) > 1 {
+			files = fmt.Sprintf("%s (+%d more)", files, len(proc.Files)-1)
+		}
+		rows = append(rows, []string{service, strconv.Itoa(proc.Pid), proc.Command, files})
+	}
+	c.outputTable([]string{"SERVICE", "PID", "COMMAND", "FILES"}, rows)
+	c.UI.Output("")
+	if len(report.Services) > 0 {
+		c.UI.Warn(fmt.Sprintf("Services needing restart: %s",
+			strings.Join(report.Services, " ")))
+	}
+	if report.Reboot {
+		c.UI.Warn("The init process uses replaced files, a reboot is recommended")
+	}
+}
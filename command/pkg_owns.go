@@ -0,0 +1,106 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Directories merged into /usr on void, mapped to their targets
+var usrMergeDirs = map[string]string{
+	"/bin":       "/usr/bin",
+	"/sbin":      "/usr/bin",
+	"/usr/sbin":  "/usr/bin",
+	"/lib":       "/usr/lib",
+	"/lib64":     "/usr/lib",
+	"/usr/lib64": "/usr/lib",
+	"/lib32":     "/usr/lib32"}
+
+type PkgOwnsCommand struct {
+	PkgCommand
+}
+
+func (c *PkgOwnsCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single path required!")
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	matches, err := c.owners(db, cOpts.Args[0])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to search package files: %s", err))
+		return exitCode
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(matches); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	if len(matches) == 0 {
+		c.UI.Error(fmt.Sprintf(
+			"No installed package owns `%s`", cOpts.Args[0]))
+		return exitCode
+	}
+	rows := [][]string{}
+	for _, match := range matches {
+		file := match.File
+		if match.Target != "" {
+			file = file + " -> " + match.Target
+		}
+		rows = append(rows, []string{match.PkgVer, match.Type, file})
+	}
+	c.outputTable([]string{"PACKAGE", "TYPE", "FILE"}, rows)
+	return 0
+}
+
+// Package files recorded for the given path
+func (c *PkgOwnsCommand) owners(db *PkgDB, path string) ([]PkgFileMatch, error) {
+	candidates := c.candidates(path)
+	c.debug(fmt.Sprintf("Looking up owners of %s", strings.Join(candidates, ", ")))
+	return db.FindFiles(func(file string) bool {
+		return c.contains(candidates, file)
+	})
+}
+
+// Paths the given path may be recorded as. Covers
+// the merged /usr directories and symlinks within the root directory.
+func (c *PkgOwnsCommand) candidates(path string) []string {
+	if !filepath.IsAbs(path) && c.RootDir == "/" {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+	}
+	path = filepath.Clean("/" + path)
+	candidates := []string{path}
+	for dir, target := range usrMergeDirs {
+		if strings.HasPrefix(path, dir+"/") {
+			candidates = append(candidates, target+strings.TrimPrefix(path, dir))
+		}
+	}
+	root := strings.TrimRight(c.RootDir, "/")
+	if resolved, err := filepath.EvalSymlinks(root + path); err == nil {
+		if root == "" || strings.HasPrefix(resolved, root+"/") {
+			resolved = strings.TrimPrefix(resolved, root)
+			if !c.contains(candidates, resolved) {
+				candidates = append(candidates, resolved)
+			}
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,83 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func fixtureOwners(t *testing.T, path string) []string {
+	c := &PkgOwnsCommand{}
+	c.RootDir = fixtureRoot
+	db := loadFixturePkgDB(t)
+	matches, err := c.owners(db, path)
+	if err != nil {
+		t.Fatalf("owners() failed: %s", err)
+	}
+	owners := []string{}
+	for _, match := range matches {
+		owners = append(owners, match.Package+":"+match.File)
+	}
+	return owners
+}
+
+func TestPkgOwns(t *testing.T) {
+	cases := []struct {
+		path   string
+		owners []string
+	}{
+		{"/usr/bin/foo", []string{"foo:/usr/bin/foo"}},
+		{"/bin/foo", []string{"foo:/usr/bin/foo"}},
+		{"/lib/libbar.so.1", []string{"libbar:/usr/lib/libbar.so.1"}},
+		{"/etc/hosts", []string{"base-files:/etc/hosts"}},
+		{"/usr/bin/foo-alias", []string{"foo:/usr/bin/foo", "foo:/usr/bin/foo-alias"}},
+		{"/usr/share/foo/[x]", []string{"foo:/usr/share/foo/[x]"}},
+		{"/usr/share/foo/x", []string{}},
+		{"/usr/share/foo/*", []string{}},
+		{"/usr/bin/[", []string{"foo:/usr/bin/["}},
+		{"/usr/bin/?", []string{}},
+	}
+	for _, tc := range cases {
+		owners := fixtureOwners(t, tc.path)
+		if len(owners) != len(tc.owners) {
+			t.Errorf("Owners of %s = %v, expected %v", tc.path, owners, tc.owners)
+			continue
+		}
+		for idx, owner := range tc.owners {
+			if owners[idx] != owner {
+				t.Errorf("Owners of %s = %v, expected %v", tc.path, owners, tc.owners)
+				break
+			}
+		}
+	}
+}
+
+func TestPkgProvides(t *testing.T) {
+	db := loadFixturePkgDB(t)
+	c := &PkgProvidesCommand{}
+	cases := []struct {
+		command string
+		files   []string
+	}{
+		{"foo", []string{"/usr/bin/foo"}},
+		{"[", []string{"/usr/bin/["}},
+		{"foo*", []string{}},
+		{"?", []string{}},
+		{"libbar.so.1", []string{}},
+	}
+	for _, tc := range cases {
+		matches, err := db.FindFiles(c.commandMatch(tc.command))
+		if err != nil {
+			t.Fatalf("FindFiles() failed: %s", err)
+		}
+		files := []string{}
+		for _, match := range matches {
+			files = append(files, match.File)
+		}
+		if strings.Join(files, " ") != strings.Join(tc.files, " ") {
+			t.Errorf("Providers of %s = %v, expected %v", tc.command, files, tc.files)
+		}
+	}
+	if escaped := escapeGlob("[*?"); escaped != `\[\*\?` {
+		t.Errorf("escapeGlob() = %s", escaped)
+	}
+}
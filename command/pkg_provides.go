@@ -0,0 +1,167 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const XBPS_QUERY_PATH = "/usr/bin/xbps-query"
+
+// Directories searched for commands
+var binDirs = []string{"/usr/bin", "/usr/sbin", "/bin", "/sbin", "/usr/local/bin"}
+
+type PkgProvidesCommand struct {
+	PkgCommand
+}
+
+func (c *PkgProvidesCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 || strings.Contains(cOpts.Args[0], "/") {
+		c.UI.Error("Single command name required!")
+		return exitCode
+	}
+	command := cOpts.Args[0]
+	match := c.commandMatch(command)
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	matches, err := db.FindFiles(match)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to search package files: %s", err))
+		return exitCode
+	}
+	if cOpts.Get("installed") == nil {
+		repoMatches, err := c.repoMatches(db, command, match, cOpts.Get("remote") != nil)
+		if err != nil {
+			c.UI.Warn(fmt.Sprintf(
+				"Failed to search repositories: %s", err))
+		}
+		matches = append(matches, repoMatches...)
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(matches); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	if len(matches) == 0 {
+		c.UI.Error(fmt.Sprintf(
+			"No package provides `%s`", command))
+		return exitCode
+	}
+	rows := [][]string{}
+	for _, match := range matches {
+		rows = append(rows, []string{match.PkgVer, match.File, match.Source})
+	}
+	c.outputTable([]string{"PACKAGE", "FILE", "SOURCE"}, rows)
+	return 0
+}
+
+// Matches files installed as the given command. Command names are
+// compared literally, `[` is a valid command.
+func (c *PkgProvidesCommand) commandMatch(command string) func(string) bool {
+	return func(path string) bool {
+		return c.contains(binDirs, filepath.Dir(path)) && filepath.Base(path) == command
+	}
+}
+
+// Escapes glob characters for patterns passed to xbps
+func escapeGlob(value string) string {
+	escaped := ""
+	for _, r := range value {
+		if strings.ContainsRune(`\*?[]`, r) {
+			escaped = escaped + `\`
+		}
+		escaped = escaped + string(r)
+	}
+	return escaped
+}
+
+// Searches repository file indexes for packages that are not
+// installed. When no repository has a file index and remote lookups
+// are enabled, xbps-query is used to search the remote packages.
+func (c *PkgProvidesCommand) repoMatches(db *PkgDB, command string, match func(string) bool, remote bool) ([]PkgFileMatch, error) {
+	matches := []PkgFileMatch{}
+	indexes, err := c.RepoIndexes()
+	if err != nil {
+		return matches, err
+	}
+	indexed := false
+	for _, index := range indexes {
+		files, err := c.repoFileIndex(index)
+		if err != nil {
+			return matches, err
+		}
+		if len(files) > 0 {
+			indexed = true
+		}
+		for name, paths := range files {
+			pkg, ok := index.Packages[name]
+			if _, installed := db.Packages[name]; installed || !ok {
+				continue
+			}
+			for _, path := range paths {
+				if match(path) {
+					matches = append(matches, PkgFileMatch{
+						Package: name,
+						PkgVer:  pkg.PkgVer,
+						File:    path,
+						Type:    "file",
+						Source:  index.URL})
+				}
+			}
+		}
+	}
+	if indexed || !remote {
+		if !indexed {
+			c.debug("No repository file indexes available, use `--remote` to query remote packages")
+		}
+		return matches, nil
+	}
+	output, err := c.xbpsOutput(XBPS_QUERY_PATH, "-R", "-o", "*/bin/"+escapeGlob(command))
+	if err != nil {
+		return matches, err
+	}
+	// Lines are formatted as `pkgver: /path/to/file (repository)`
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ": ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name, _, err := splitPkgVer(parts[0])
+		if err != nil {
+			continue
+		}
+		if _, installed := db.Packages[name]; installed {
+			continue
+		}
+		fields := strings.Fields(parts[1])
+		if len(fields) == 0 || !match(fields[0]) {
+			continue
+		}
+		source := "remote"
+		if len(fields) > 1 {
+			source = strings.Trim(fields[1], "()")
+		}
+		matches = append(matches, PkgFileMatch{
+			Package: name,
+			PkgVer:  parts[0],
+			File:    fields[0],
+			Type:    "file",
+			Source:  source})
+	}
+	return matches, nil
+}
@@ -0,0 +1,204 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const XBPS_RECONFIGURE_PATH = "/usr/bin/xbps-reconfigure"
+const VOID_RECONFIGURE_LOG_PATH = "/var/log/void/reconfigure"
+
+type PkgReconfigureCommand struct {
+	PkgCommand
+}
+
+// Outcome of reconfiguring a single package
+type ReconfigureResult struct {
+	Name     string `json:"name"`
+	PkgVer   string `json:"pkgver"`
+	Previous string `json:"previous_state"`
+	State    string `json:"state"`
+	Success  bool   `json:"success"`
+	Output   string `json:"output"`
+	LogFile  string `json:"log_file,omitempty"`
+}
+
+func (c *PkgReconfigureCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	unpacked := []*Package{}
+	for _, name := range db.Names() {
+		if db.Packages[name].State == "unpacked" {
+			unpacked = append(unpacked, db.Packages[name])
+		}
+	}
+	names := cOpts.Args
+	if cOpts.Get("all-unpacked") != nil {
+		if len(names) > 0 {
+			c.UI.Error("Package names cannot be used with `--all-unpacked`!")
+			return exitCode
+		}
+		for _, pkg := range unpacked {
+			names = append(names, pkg.Name)
+		}
+		if len(names) == 0 {
+			c.UI.Info("No packages pending reconfiguration")
+			return 0
+		}
+	} else if len(names) == 0 {
+		return c.listUnpacked(unpacked, cOpts.Get("json") != nil)
+	}
+	for _, name := range names {
+		if _, ok := db.Packages[name]; !ok {
+			c.UI.Error(fmt.Sprintf(
+				"Package `%s` is not installed!", name))
+			return exitCode
+		}
+	}
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	logDir := filepath.Join(c.RootDir, VOID_RECONFIGURE_LOG_PATH)
+	if flag := cOpts.Get("log-dir"); flag != nil && flag.Value != "" {
+		logDir = flag.Value
+	}
+	force := cOpts.Get("force") != nil
+	results := []*ReconfigureResult{}
+	failed := 0
+	for _, name := range names {
+		result := c.reconfigure(db.Packages[name], force)
+		if path, err := c.writeLog(logDir, result); err != nil {
+			c.UI.Warn(fmt.Sprintf(
+				"Failed to write reconfigure log for `%s`: %s", name, err))
+		} else {
+			result.LogFile = path
+		}
+		if !result.Success {
+			failed++
+		}
+		results = append(results, result)
+		if cOpts.Get("json") != nil {
+			continue
+		}
+		if result.Success {
+			c.UI.Info(fmt.Sprintf("Reconfigured package: %s", result.PkgVer))
+			if cOpts.Get("verbose") != nil && result.Output != "" {
+				c.UI.Output(indentLines(result.Output, "    "))
+			}
+		} else {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to reconfigure package: %s (state: %s)", result.PkgVer, result.State))
+			if result.Output != "" {
+				c.UI.Output(indentLines(result.Output, "    "))
+			}
+			if result.LogFile != "" {
+				c.UI.Output(fmt.Sprintf("    Log: %s", result.LogFile))
+			}
+		}
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(results); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+	} else if len(results) > 1 {
+		c.UI.Output("")
+		c.UI.Output(fmt.Sprintf("Reconfigured %d of %d packages",
+			len(results)-failed, len(results)))
+	}
+	if failed > 0 {
+		return exitCode
+	}
+	return 0
+}
+
+func (c *PkgReconfigureCommand) listUnpacked(unpacked []*Package, asJSON bool) int {
+	if asJSON {
+		if err := c.outputJSON(unpacked); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return 1
+		}
+		return 0
+	}
+	if len(unpacked) == 0 {
+		c.UI.Info("No packages pending reconfiguration")
+		return 0
+	}
+	rows := [][]string{}
+	for _, pkg := range unpacked {
+		rows = append(rows, []string{pkg.Name, pkg.Version, pkg.State, pkg.InstallDate})
+	}
+	c.outputTable([]string{"NAME", "VERSION", "STATE", "INSTALLED"}, rows)
+	c.UI.Output("")
+	c.UI.Warn(fmt.Sprintf(
+		"%d packages are not configured (run `%s pkg reconfigure --all-unpacked`)", len(unpacked), c.AppName))
+	return 0
+}
+
+// Reconfigures a single package capturing the output of its INSTALL
+// script. The resulting state is read back from the pkgdb since
+// xbps-reconfigure does not fail for every script error.
+func (c *PkgReconfigureCommand) reconfigure(pkg *Package, force bool) *ReconfigureResult {
+	result := &ReconfigureResult{
+		Name:     pkg.Name,
+		PkgVer:   pkg.PkgVer,
+		Previous: pkg.State,
+		State:    pkg.State}
+	xArgs := c.xbpsRootArgs()
+	if force {
+		xArgs = append(xArgs, "-f")
+	}
+	var output bytes.Buffer
+	cmd := exec.Command(XBPS_RECONFIGURE_PATH, append(xArgs, pkg.Name)...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	exitCode := c.ExecuteCommand(cmd)
+	result.Output = strings.TrimSpace(output.String())
+	if db, err := c.PkgDB(); err == nil {
+		if current, ok := db.Packages[pkg.Name]; ok {
+			result.State = current.State
+		}
+	}
+	result.Success = exitCode == 0 && result.State == "installed"
+	return result
+}
+
+func (c *PkgReconfigureCommand) writeLog(dir string, result *ReconfigureResult) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	status := "success"
+	if !result.Success {
+		status = "failed"
+	}
+	content := fmt.Sprintf("package: %s\nstate: %s -> %s\nresult: %s\n\n%s\n",
+		result.PkgVer, result.Previous, result.State, status, result.Output)
+	path := filepath.Join(dir, result.PkgVer+".log")
+	return path, writeFileAtomic(path, []byte(content), 0644)
+}
+
+func indentLines(text string, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,28 @@
+package command
+
+import (
+	"fmt"
+)
+
+type PkgRemoveCommand struct {
+	PkgCommand
+}
+
+func (c *PkgRemoveCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) == 0 {
+		c.UI.Error("At least one package name required!")
+		return exitCode
+	}
+	xArgs := []string{}
+	if cOpts.Get("recursive") != nil {
+		xArgs = append(xArgs, "-R")
+	}
+	return c.RunTransaction(XBPS_REMOVE_PATH, append(xArgs, cOpts.Args...), cOpts)
+}
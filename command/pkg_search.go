@@ -0,0 +1,164 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type PkgSearchCommand struct {
+	PkgCommand
+}
+
+// Package matched by a search, with installation status
+type PkgSearchResult struct {
+	Package
+	Installed        bool   `json:"installed"`
+	InstalledVersion string `json:"installed_version,omitempty"`
+	score            int
+}
+
+func (c *PkgSearchCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single search term required!")
+		return exitCode
+	}
+	matcher, err := c.matcher(cOpts.Args[0], cOpts.Get("fuzzy") != nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Invalid search term: %s", err))
+		return exitCode
+	}
+	indexes, err := c.RepoIndexes()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load repository indexes: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	results := c.search(indexes, db, matcher, cOpts.Get("installed") != nil)
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(results); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	if len(results) == 0 {
+		c.UI.Warn("No matching packages found")
+		return 0
+	}
+	rows := [][]string{}
+	for _, result := range results {
+		mark := "[-]"
+		if result.Installed {
+			mark = "[*]"
+		}
+		rows = append(rows, []string{mark, result.Name, result.Version, result.ShortDesc})
+	}
+	c.outputTable(nil, rows)
+	return 0
+}
+
+// Matches the packages of all indexes, ordered by score and name. A
+// package found in several repositories is only taken from the first.
+func (c *PkgSearchCommand) search(indexes []*RepoIndex, db *PkgDB, matcher func(*Package) (int, bool), installedOnly bool) []*PkgSearchResult {
+	results := []*PkgSearchResult{}
+	seen := map[string]bool{}
+	for _, index := range indexes {
+		for name, pkg := range index.Packages {
+			if seen[name] {
+				continue
+			}
+			score, ok := matcher(pkg)
+			if !ok {
+				continue
+			}
+			seen[name] = true
+			result := &PkgSearchResult{Package: *pkg, score: score}
+			if installed, ok := db.Packages[name]; ok {
+				result.Installed = true
+				result.InstalledVersion = installed.Version
+			}
+			if installedOnly && !result.Installed {
+				continue
+			}
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score < results[j].score
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// Builds a matcher for the search term. Matchers return a score used
+// for ordering (lower is better) and if the package matched.
+func (c *PkgSearchCommand) matcher(term string, fuzzy bool) (func(*Package) (int, bool), error) {
+	if term == "" {
+		return nil, errors.New("Search term cannot be empty")
+	}
+	if fuzzy {
+		term = strings.ToLower(term)
+		return func(pkg *Package) (int, bool) {
+			if score, ok := fuzzyScore(term, strings.ToLower(pkg.Name)); ok {
+				return score, true
+			}
+			if score, ok := fuzzyScore(term, strings.ToLower(pkg.ShortDesc)); ok {
+				// Description matches rank below any name match
+				return score + len(pkg.Name) + 1000, true
+			}
+			return 0, false
+		}, nil
+	}
+	re, err := regexp.Compile("(?i)" + term)
+	if err != nil {
+		return nil, err
+	}
+	return func(pkg *Package) (int, bool) {
+		if re.MatchString(pkg.Name) {
+			return 0, true
+		}
+		return 1, re.MatchString(pkg.ShortDesc)
+	}, nil
+}
+
+// Matches the pattern as a subsequence of the text. The score is the
+// number of skipped characters, so tighter matches rank first.
+func fuzzyScore(pattern string, text string) (int, bool) {
+	score := 0
+	started := false
+	for _, r := range pattern {
+		idx := strings.IndexRune(text, r)
+		if idx == -1 {
+			return 0, false
+		}
+		if started {
+			score = score + idx
+		} else {
+			score = score + idx/2
+			started = true
+		}
+		text = text[idx+utf8.RuneLen(r):]
+	}
+	return score, true
+}
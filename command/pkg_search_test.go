@@ -0,0 +1,57 @@
+package command
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestPkgSearch(t *testing.T) {
+	os.Setenv("XBPS_ARCH", "x86_64")
+	defer os.Unsetenv("XBPS_ARCH")
+	c := &PkgSearchCommand{}
+	c.RootDir = fixtureRoot
+	indexes, err := c.RepoIndexes()
+	if err != nil {
+		t.Fatalf("RepoIndexes() failed: %s", err)
+	}
+	if len(indexes) != 1 || len(indexes[0].Packages) != 4 {
+		t.Fatalf("RepoIndexes() = %+v, expected one index of 4 packages", indexes)
+	}
+	if foo := indexes[0].Packages["foo"]; foo == nil || foo.Version != "1.1_1" ||
+		foo.Repository != "https://repo-default.voidlinux.org/current" {
+		t.Errorf("Indexed foo package = %+v", foo)
+	}
+	db := loadFixturePkgDB(t)
+	cases := []struct {
+		term          string
+		fuzzy         bool
+		installedOnly bool
+		expected      []string
+	}{
+		{"foo", false, false, []string{"foo", "foobar-utils", "zsh"}},
+		{"^LIB", false, false, []string{"libbar"}},
+		{"foo", false, true, []string{"foo"}},
+		{"fbr", true, false, []string{"foobar-utils"}},
+		{"missing", false, false, []string{}},
+	}
+	for _, tc := range cases {
+		matcher, err := c.matcher(tc.term, tc.fuzzy)
+		if err != nil {
+			t.Fatalf("matcher(%q) failed: %s", tc.term, err)
+		}
+		names := []string{}
+		for _, result := range c.search(indexes, db, matcher, tc.installedOnly) {
+			names = append(names, result.Name)
+			if result.Name == "foo" && (!result.Installed || result.InstalledVersion != "1.0_1") {
+				t.Errorf("search(%q) foo installed = %t (%s)", tc.term, result.Installed, result.InstalledVersion)
+			}
+		}
+		if !reflect.DeepEqual(names, tc.expected) {
+			t.Errorf("search(%q) = %v, expected %v", tc.term, names, tc.expected)
+		}
+	}
+	if _, err := c.matcher("(", false); err == nil {
+		t.Errorf("matcher() accepted an invalid expression")
+	}
+}
@@ -0,0 +1,153 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type PkgSizeCommand struct {
+	PkgCommand
+}
+
+// Installed size of a package and what removing it would free
+type PkgSize struct {
+	Name          string   `json:"name"`
+	Version       string   `json:"version"`
+	Automatic     bool     `json:"automatic"`
+	InstalledSize int64    `json:"installed_size"`
+	Exclusive     []string `json:"exclusive_depends,omitempty"`
+	ExclusiveSize int64    `json:"exclusive_size,omitempty"`
+	TotalSize     int64    `json:"total_size"`
+}
+
+func (c *PkgSizeCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	limit := 0
+	if flag := cOpts.Get("limit"); flag != nil {
+		if limit, err = strconv.Atoi(flag.Value); err != nil || limit < 0 {
+			c.UI.Error(fmt.Sprintf(
+				"Invalid limit value `%s`", flag.Value))
+			return exitCode
+		}
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	names := cOpts.Args
+	if len(names) == 0 {
+		names = db.Names()
+	}
+	for _, name := range names {
+		if _, ok := db.Packages[name]; !ok {
+			c.UI.Error(fmt.Sprintf(
+				"Package `%s` is not installed!", name))
+			return exitCode
+		}
+	}
+	sizes := c.installedSizes(db)
+	exclusive := cOpts.Get("exclusive") != nil
+	revDeps := db.ReverseDepends()
+	results := []*PkgSize{}
+	var total int64
+	for _, name := range names {
+		pkg := db.Packages[name]
+		result := &PkgSize{
+			Name:          name,
+			Version:       pkg.Version,
+			Automatic:     pkg.Automatic,
+			InstalledSize: sizes[name]}
+		result.TotalSize = result.InstalledSize
+		if exclusive {
+			result.Exclusive = db.ExclusiveDepends(name, revDeps)
+			for _, dep := range result.Exclusive {
+				result.ExclusiveSize = result.ExclusiveSize + sizes[dep]
+			}
+			result.TotalSize = result.TotalSize + result.ExclusiveSize
+		}
+		total = total + result.InstalledSize
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].TotalSize != results[j].TotalSize {
+			return results[i].TotalSize > results[j].TotalSize
+		}
+		return results[i].Name < results[j].Name
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(results); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	header := []string{"NAME", "VERSION", "MODE", "SIZE"}
+	if exclusive {
+		header = append(header, "EXCLUSIVE", "FREED", "DEPENDENCIES")
+	}
+	rows := [][]string{}
+	for _, result := range results {
+		mode := "manual"
+		if result.Automatic {
+			mode = "auto"
+		}
+		row := []string{result.Name, result.Version, mode, humanSize(result.InstalledSize)}
+		if exclusive {
+			row = append(row, humanSize(result.ExclusiveSize), humanSize(result.TotalSize),
+				strings.Join(result.Exclusive, ","))
+		}
+		rows = append(rows, row)
+	}
+	c.outputTable(header, rows)
+	c.UI.Output("")
+	c.UI.Output(fmt.Sprintf("Total installed size: %s (%d packages)", humanSize(total), len(names)))
+	return 0
+}
+
+// Installed sizes of all packages. Sizes missing from the pkgdb are
+// taken from the repository index when the same version is available.
+func (c *PkgSizeCommand) installedSizes(db *PkgDB) map[string]int64 {
+	sizes := map[string]int64{}
+	missing := false
+	for name, pkg := range db.Packages {
+		sizes[name] = pkg.InstalledSize
+		if pkg.InstalledSize == 0 {
+			missing = true
+		}
+	}
+	if !missing {
+		return sizes
+	}
+	indexes, err := c.RepoIndexes()
+	if err != nil {
+		c.debug(fmt.Sprintf(
+			"Unable to load repository indexes for size lookup: %s", err))
+		return sizes
+	}
+	for name, pkg := range db.Packages {
+		if sizes[name] != 0 {
+			continue
+		}
+		for _, index := range indexes {
+			if repoPkg, ok := index.Packages[name]; ok && repoPkg.PkgVer == pkg.PkgVer {
+				sizes[name] = repoPkg.InstalledSize
+				break
+			}
+		}
+	}
+	return sizes
+}
@@ -0,0 +1,251 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const XBPS_INSTALL_PATH = "/usr/bin/xbps-install"
+const XBPS_REMOVE_PATH = "/usr/bin/xbps-remove"
+
+// Single package action from an xbps dry run
+type PkgTransactionItem struct {
+	PkgVer          string `json:"pkgver"`
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	PreviousVersion string `json:"previous_version,omitempty"`
+	Action          string `json:"action"`
+	Architecture    string `json:"architecture,omitempty"`
+	Repository      string `json:"repository,omitempty"`
+	InstalledSize   int64  `json:"installed_size"`
+	DownloadSize    int64  `json:"download_size"`
+	SizeDelta       int64  `json:"size_delta"`
+}
+
+// Parsed transaction preview
+type PkgTransaction struct {
+	Items        []PkgTransactionItem `json:"items"`
+	DownloadSize int64                `json:"download_size"`
+	SizeDelta    int64                `json:"size_delta"`
+}
+
+// Actions reported by xbps dry runs
+var transactionActions = []string{
+	"install", "update", "downgrade", "remove", "configure", "reinstall", "hold", "download"}
+
+// Parses xbps dry run output. Each line has the form:
+// `pkgver action arch repository installed_size [download_size]`
+// Other lines (progress, warnings) are ignored.
+func parseTransaction(output string, db *PkgDB) *PkgTransaction {
+	trans := &PkgTransaction{Items: []PkgTransactionItem{}}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || !transactionActionKnown(fields[1]) {
+			continue
+		}
+		name, version, err := splitPkgVer(fields[0])
+		if err != nil {
+			continue
+		}
+		item := PkgTransactionItem{
+			PkgVer:  fields[0],
+			Name:    name,
+			Version: version,
+			Action:  fields[1]}
+		item.Architecture = fields[2]
+		item.Repository = fields[3]
+		item.InstalledSize, _ = strconv.ParseInt(fields[4], 10, 64)
+		if len(fields) > 5 {
+			item.DownloadSize, _ = strconv.ParseInt(fields[5], 10, 64)
+		}
+		installed, isInstalled := db.Packages[name]
+		switch item.Action {
+		case "install":
+			item.SizeDelta = item.InstalledSize
+		case "update", "downgrade":
+			if isInstalled {
+				item.PreviousVersion = installed.Version
+				item.SizeDelta = item.InstalledSize - installed.InstalledSize
+			} else {
+				item.SizeDelta = item.InstalledSize
+			}
+		case "remove":
+			if isInstalled {
+				item.SizeDelta = -installed.InstalledSize
+				if item.InstalledSize == 0 {
+					item.InstalledSize = installed.InstalledSize
+				}
+			} else {
+				item.SizeDelta = -item.InstalledSize
+			}
+		}
+		trans.DownloadSize = trans.DownloadSize + item.DownloadSize
+		trans.SizeDelta = trans.SizeDelta + item.SizeDelta
+		trans.Items = append(trans.Items, item)
+	}
+	return trans
+}
+
+// Formats a signed size delta for display
+func humanSizeDelta(size int64) string {
+	if size < 0 {
+		return "-" + humanSize(-size)
+	}
+	return "+" + humanSize(size)
+}
+
+// Arguments pointing xbps tools at the configured root directory
+func (c *PkgCommand) xbpsRootArgs() []string {
+	if c.RootDir == "" || c.RootDir == "/" {
+		return []string{}
+	}
+	return []string{"-r", c.RootDir}
+}
+
+// Runs an xbps tool capturing its output. Captured error output is
+// returned as the error message when the command fails.
+func (c *PkgCommand) xbpsOutput(tool string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(tool, append(c.xbpsRootArgs(), args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if exitCode := c.ExecuteCommand(cmd); exitCode != 0 {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			msg = fmt.Sprintf("exit code %d", exitCode)
+		}
+		return stdout.String(), fmt.Errorf("`%s` failed: %s", tool, msg)
+	}
+	return stdout.String(), nil
+}
+
+// Synchronizes the remote repository indexes, showing xbps progress
+func (c *PkgCommand) syncRepositories() bool {
+	cmd := exec.Command(XBPS_INSTALL_PATH, append(c.xbpsRootArgs(), "-S")...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return c.ExecuteCommand(cmd) == 0
+}
+
+// Previews the transaction using the dry run mode of the given xbps
+// tool, asks for confirmation and then runs it. Used by the install,
+// remove and update commands.
+func (c *PkgCommand) RunTransaction(tool string, args []string, cOpts ParsedCli) int {
+	exitCode := 1
+	dryRun := cOpts.Get("dry-run") != nil
+	if !dryRun && !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	// Synchronize once before the preview so it reflects the new index,
+	// the dry run of a non-root user cannot write the index
+	if cOpts.Get("sync") != nil {
+		if c.isRoot() {
+			if !c.syncRepositories() {
+				c.UI.Error("Failed to synchronize repository indexes!")
+				return exitCode
+			}
+		} else {
+			c.UI.Warn("Skipping repository sync, this requires `root`")
+		}
+	}
+	output, err := c.xbpsOutput(tool, append([]string{"-n"}, args...)...)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to compute transaction: %s", err))
+		return exitCode
+	}
+	trans := parseTransaction(output, db)
+	if len(trans.Items) == 0 {
+		c.UI.Info("Nothing to do.")
+		return 0
+	}
+	c.showTransaction(trans)
+	if dryRun {
+		return 0
+	}
+	if cOpts.Get("yes") == nil {
+		ok, err := c.confirm("Proceed with this transaction?")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to read confirmation: %s", err))
+			return exitCode
+		}
+		if !ok {
+			c.UI.Warn("Transaction aborted.")
+			return exitCode
+		}
+	}
+	// Run attached to the terminal so download and unpack progress is
+	// shown and repository key imports can be confirmed
+	cmd := exec.Command(tool, append(c.xbpsRootArgs(), append([]string{"-y"}, args...)...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if result := c.ExecuteCommand(cmd); result != 0 {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to run transaction: `%s` exited with code %d", tool, result))
+		return exitCode
+	}
+	for _, item := range trans.Items {
+		c.UI.Info(fmt.Sprintf(
+			"%s package: %s", c.actionResult(item.Action), item.PkgVer))
+	}
+	return 0
+}
+
+func (c *PkgCommand) showTransaction(trans *PkgTransaction) {
+	rows := [][]string{}
+	for _, item := range trans.Items {
+		version := item.Version
+		if item.PreviousVersion != "" {
+			version = item.PreviousVersion + " -> " + item.Version
+		}
+		rows = append(rows, []string{
+			item.Action, item.Name, version,
+			humanSize(item.DownloadSize), humanSizeDelta(item.SizeDelta)})
+	}
+	c.outputTable([]string{"ACTION", "NAME", "VERSION", "DOWNLOAD", "SIZE"}, rows)
+	c.UI.Output("")
+	c.UI.Output(fmt.Sprintf("Download size:         %s", humanSize(trans.DownloadSize)))
+	c.UI.Output(fmt.Sprintf("Installed size change: %s", humanSizeDelta(trans.SizeDelta)))
+}
+
+func (c *PkgCommand) actionResult(action string) string {
+	switch action {
+	case "install":
+		return "Installed"
+	case "update":
+		return "Updated"
+	case "downgrade":
+		return "Downgraded"
+	case "remove":
+		return "Removed"
+	case "configure":
+		return "Configured"
+	}
+	return strings.ToUpper(action[:1]) + action[1:]
+}
+
+func transactionActionKnown(action string) bool {
+	for _, known := range transactionActions {
+		if action == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"testing"
+)
+
+func TestParseTransaction(t *testing.T) {
+	db := loadFixturePkgDB(t)
+	output := `[*] Updating repository index
+foo-1.1_1 update x86_64 https://repo-default.voidlinux.org/current 4096 1024
+newpkg-2.0_1 install x86_64 https://repo-default.voidlinux.org/current 512 256
+libbar-1.2_1 remove x86_64 installed 0
+WARNING: foo-1.1_1 has a changed configuration file
+baz-1.0_1 frobnicate x86_64 repo 1 1
+short-1.0_1 install
+`
+	trans := parseTransaction(output, db)
+	expected := []string{"foo-1.1_1", "newpkg-2.0_1", "libbar-1.2_1"}
+	if len(trans.Items) != len(expected) {
+		t.Fatalf("parseTransaction() returned %d items, expected %d", len(trans.Items), len(expected))
+	}
+	for idx, pkgver := range expected {
+		if trans.Items[idx].PkgVer != pkgver {
+			t.Errorf("Item %d = %s, expected %s", idx, trans.Items[idx].PkgVer, pkgver)
+		}
+	}
+	if foo := trans.Items[0]; foo.PreviousVersion != "1.0_1" || foo.SizeDelta != 2048 {
+		t.Errorf("foo update = %+v", foo)
+	}
+	if trans.DownloadSize != 1280 {
+		t.Errorf("Download size = %d, expected 1280", trans.DownloadSize)
+	}
+}
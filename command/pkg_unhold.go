@@ -0,0 +1,74 @@
+package command
+
+import (
+	"fmt"
+)
+
+type PkgUnholdCommand struct {
+	PkgCommand
+}
+
+func (c *PkgUnholdCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) == 0 {
+		c.UI.Error("At least one package name required!")
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	notes, err := LoadHoldNotes(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load hold notes: %s", err))
+		return exitCode
+	}
+	for _, name := range cOpts.Args {
+		pkg, ok := db.Packages[name]
+		if !ok {
+			c.UI.Error(fmt.Sprintf(
+				"Package `%s` is not installed!", name))
+			return exitCode
+		}
+		if !pkg.Hold && !pkg.RepoLock {
+			c.UI.Error(fmt.Sprintf(
+				"Package `%s` is not held!", name))
+			return exitCode
+		}
+	}
+	for _, name := range cOpts.Args {
+		pkg := db.Packages[name]
+		if pkg.Hold && !c.SetPkgMode(name, "unhold") {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to unhold package `%s`!", name))
+			return exitCode
+		}
+		if pkg.RepoLock && !c.SetPkgMode(name, "repounlock") {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to unlock repository of package `%s`!", name))
+			return exitCode
+		}
+		delete(notes, name)
+		c.UI.Info(fmt.Sprintf(
+			"Released package: %s", pkg.PkgVer))
+	}
+	if err := notes.Save(c.RootDir); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to save hold notes: %s", err))
+		return exitCode
+	}
+	return 0
+}
@@ -0,0 +1,21 @@
+package command
+
+import (
+	"fmt"
+)
+
+type PkgUpdateCommand struct {
+	PkgCommand
+}
+
+func (c *PkgUpdateCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	xArgs := []string{"-u"}
+	return c.RunTransaction(XBPS_INSTALL_PATH, append(xArgs, cOpts.Args...), cOpts)
+}
@@ -0,0 +1,225 @@
+package command
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type PkgVerifyCommand struct {
+	PkgCommand
+}
+
+// Installed file not matching its package metadata
+type VerifyProblem struct {
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Result of a verification run
+type VerifyReport struct {
+	Packages int `json:"packages"`
+	Files    int `json:"files"`
+	Failures int `json:"failures"`
+	// Packages whose file modes could not be checked
+	Unverified int             `json:"unverified"`
+	Problems   []VerifyProblem `json:"problems"`
+}
+
+func (c *PkgVerifyCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup package command: %s", err))
+		return exitCode
+	}
+	db, err := c.PkgDB()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load package database: %s", err))
+		return exitCode
+	}
+	names := cOpts.Args
+	if len(names) == 0 {
+		names = db.Names()
+	}
+	for _, name := range names {
+		if _, ok := db.Packages[name]; !ok {
+			c.UI.Error(fmt.Sprintf(
+				"Package `%s` is not installed!", name))
+			return exitCode
+		}
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	strict := cOpts.Get("strict") != nil
+	jsonOutput := cOpts.Get("json") != nil
+	report := &VerifyReport{Problems: []VerifyProblem{}}
+	for _, name := range names {
+		files, err := db.Files(name)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to load package files: %s", err))
+			return exitCode
+		}
+		modes, err := c.packagedModes(db.Packages[name], config.CacheDir())
+		// Pruned caches are common, so unverified modes are only listed
+		// individually when strict or for JSON output
+		if err != nil {
+			report.Unverified++
+			if strict {
+				report.Failures++
+			}
+			if strict || jsonOutput {
+				report.Problems = append(report.Problems, VerifyProblem{
+					Package: name,
+					Status:  "mode-unverified",
+					Detail:  err.Error()})
+			}
+		}
+		report.Packages++
+		for _, file := range files.Files {
+			report.Files++
+			c.addProblem(report, name, file, c.verifyFile(file, modes), true)
+		}
+		for _, file := range files.ConfFiles {
+			report.Files++
+			c.addProblem(report, name, file, c.verifyFile(file, modes), false)
+		}
+		for _, file := range files.Links {
+			report.Files++
+			c.addProblem(report, name, file, c.verifyLink(file), true)
+		}
+	}
+	if jsonOutput {
+		if err := c.outputJSON(report); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+	} else {
+		if len(report.Problems) > 0 {
+			rows := [][]string{}
+			for _, problem := range report.Problems {
+				rows = append(rows, []string{problem.Status, problem.File, problem.Package, problem.Detail})
+			}
+			c.outputTable([]string{"STATUS", "FILE", "PACKAGE", "DETAIL"}, rows)
+			c.UI.Output("")
+		}
+		summary := fmt.Sprintf("Verified %d files in %d packages: %d failures",
+			report.Files, report.Packages, report.Failures)
+		if report.Unverified > 0 {
+			summary = fmt.Sprintf("%s (modes not verified for %d packages, use `--strict` to list them)",
+				summary, report.Unverified)
+		}
+		if report.Failures > 0 {
+			c.UI.Error(summary)
+		} else {
+			c.UI.Info(summary)
+		}
+	}
+	if report.Failures > 0 {
+		return exitCode
+	}
+	return 0
+}
+
+// Records a problem. Modified configuration files are reported but
+// not counted as failures since they are meant to be edited.
+func (c *PkgVerifyCommand) addProblem(report *VerifyReport, name string, file PkgFile, problem *VerifyProblem, strict bool) {
+	if problem == nil {
+		return
+	}
+	problem.Package = name
+	problem.File = file.Path
+	if !strict && problem.Status == "modified" {
+		problem.Status = "modified-conf"
+	} else {
+		report.Failures++
+	}
+	report.Problems = append(report.Problems, *problem)
+}
+
+func (c *PkgVerifyCommand) verifyFile(file PkgFile, modes map[string]*tar.Header) *VerifyProblem {
+	path := filepath.Join(c.RootDir, file.Path)
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &VerifyProblem{Status: "missing"}
+		}
+		return &VerifyProblem{Status: "unreadable", Detail: err.Error()}
+	}
+	if !info.Mode().IsRegular() {
+		return &VerifyProblem{Status: "type", Detail: "not a regular file"}
+	}
+	if file.SHA256 != "" {
+		digest, err := fileSHA256(path)
+		if err != nil {
+			return &VerifyProblem{Status: "unreadable", Detail: err.Error()}
+		}
+		if digest != file.SHA256 {
+			return &VerifyProblem{Status: "modified", Detail: "sha256 mismatch"}
+		}
+	}
+	if header, ok := modes[file.Path]; ok {
+		expected := os.FileMode(header.Mode).Perm()
+		if info.Mode().Perm() != expected {
+			return &VerifyProblem{
+				Status: "mode",
+				Detail: fmt.Sprintf("%04o (expected %04o)", info.Mode().Perm(), expected)}
+		}
+	}
+	return nil
+}
+
+func (c *PkgVerifyCommand) verifyLink(file PkgFile) *VerifyProblem {
+	path := filepath.Join(c.RootDir, file.Path)
+	target, err := os.Readlink(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &VerifyProblem{Status: "missing"}
+		}
+		return &VerifyProblem{Status: "type", Detail: "not a symlink"}
+	}
+	if file.Target == "" || target == file.Target {
+		return nil
+	}
+	// Accept targets that resolve to the same location
+	if c.linkDest(file.Path, target) == c.linkDest(file.Path, file.Target) {
+		return nil
+	}
+	return &VerifyProblem{
+		Status: "link",
+		Detail: fmt.Sprintf("points to %s (expected %s)", target, file.Target)}
+}
+
+func (c *PkgVerifyCommand) linkDest(link string, target string) string {
+	if filepath.IsAbs(target) {
+		return filepath.Clean(target)
+	}
+	return filepath.Join(filepath.Dir(link), target)
+}
+
+// File modes recorded in the cached package archive. An error is
+// returned when the archive is not cached or cannot be read, in which
+// case modes are not checked.
+func (c *PkgVerifyCommand) packagedModes(pkg *Package, cacheDir string) (map[string]*tar.Header, error) {
+	archives, _ := filepath.Glob(filepath.Join(cacheDir, pkg.PkgVer+".*.xbps"))
+	if len(archives) == 0 {
+		return map[string]*tar.Header{}, errors.New("package archive not cached")
+	}
+	headers, err := c.archiveHeaders(archives[0])
+	if err != nil {
+		return map[string]*tar.Header{}, fmt.Errorf("failed to read package archive: %s", err)
+	}
+	return headers, nil
+}
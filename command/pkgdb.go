@@ -0,0 +1,409 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const XBPS_DB_PATH = "/var/db/xbps"
+const XBPS_PKGDB_FILE = "pkgdb-0.38.plist"
+const XBPS_ALTERNATIVES_KEY = "_XBPS_ALTERNATIVES_"
+
+// Installed package as recorded in the xbps pkgdb
+type Package struct {
+	Name          string              `json:"name"`
+	Version       string              `json:"version"`
+	PkgVer        string              `json:"pkgver"`
+	ShortDesc     string              `json:"short_desc,omitempty"`
+	Architecture  string              `json:"architecture,omitempty"`
+	Repository    string              `json:"repository,omitempty"`
+	Homepage      string              `json:"homepage,omitempty"`
+	License       string              `json:"license,omitempty"`
+	Maintainer    string              `json:"maintainer,omitempty"`
+	InstallDate   string              `json:"install_date,omitempty"`
+	State         string              `json:"state"`
+	Automatic     bool                `json:"automatic"`
+	Hold          bool                `json:"hold"`
+	RepoLock      bool                `json:"repolock"`
+	HoldReason    string              `json:"hold_reason,omitempty"`
+	InstalledSize int64               `json:"installed_size"`
+	FilenameSize  int64               `json:"filename_size,omitempty"`
+	RunDepends    []string            `json:"run_depends,omitempty"`
+	Provides      []string            `json:"provides,omitempty"`
+	ShlibProvides []string            `json:"shlib_provides,omitempty"`
+	ShlibRequires []string            `json:"shlib_requires,omitempty"`
+	Alternatives  map[string][]string `json:"alternatives,omitempty"`
+	ConfFiles     []PkgFile           `json:"conf_files,omitempty"`
+}
+
+// File entry from a package files metadata plist
+type PkgFile struct {
+	Path   string `json:"file"`
+	SHA256 string `json:"sha256,omitempty"`
+	Target string `json:"target,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+}
+
+// Contents of a package files metadata plist
+type PkgFiles struct {
+	Files     []PkgFile
+	ConfFiles []PkgFile
+	Links     []PkgFile
+	Dirs      []PkgFile
+}
+
+// Package file matched by a file search
+type PkgFileMatch struct {
+	Package string `json:"package"`
+	PkgVer  string `json:"pkgver"`
+	File    string `json:"file"`
+	Target  string `json:"target,omitempty"`
+	Type    string `json:"type"`
+	Source  string `json:"source"`
+}
+
+// Parsed xbps package database
+type PkgDB struct {
+	Path         string
+	RootDir      string
+	Packages     map[string]*Package
+	Alternatives map[string][]string
+	virtuals     map[string]string
+	// Non fatal problems found while loading
+	warnings []string
+}
+
+// Loads the pkgdb plist below the given root directory. The format
+// used by current xbps is preferred, otherwise the newest format found.
+func LoadPkgDB(rootDir string) (*PkgDB, error) {
+	dbDir := filepath.Join(rootDir, XBPS_DB_PATH)
+	path := filepath.Join(dbDir, XBPS_PKGDB_FILE)
+	if _, err := os.Stat(path); err != nil {
+		matches, err := filepath.Glob(filepath.Join(dbDir, "pkgdb-*.plist"))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("No package database found in `%s`", dbDir)
+		}
+		path = matches[0]
+		for _, match := range matches[1:] {
+			if compareVersions(pkgDBFormat(match), pkgDBFormat(path)) > 0 {
+				path = match
+			}
+		}
+	}
+	db := &PkgDB{
+		Path:         path,
+		RootDir:      rootDir,
+		Packages:     map[string]*Package{},
+		Alternatives: map[string][]string{},
+		virtuals:     map[string]string{}}
+	content, err := ParsePlistFile(db.Path)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range content {
+		dict, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name == XBPS_ALTERNATIVES_KEY {
+			for group := range dict {
+				db.Alternatives[group] = plistStrings(dict, group)
+			}
+			continue
+		}
+		db.Packages[name] = newPackage(name, dict)
+	}
+	for _, name := range db.Names() {
+		if err := db.loadConfFiles(db.Packages[name]); err != nil {
+			db.warnings = append(db.warnings, fmt.Sprintf(
+				"Failed to load configuration file hashes of `%s`: %s", name, err))
+		}
+		for _, provided := range db.Packages[name].Provides {
+			virtual := depPatternName(provided)
+			if _, ok := db.virtuals[virtual]; !ok {
+				db.virtuals[virtual] = name
+			}
+		}
+	}
+	return db, nil
+}
+
+// Format version of a pkgdb plist from its file name
+func pkgDBFormat(path string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "pkgdb-"), ".plist")
+}
+
+func newPackage(name string, dict map[string]interface{}) *Package {
+	pkg := &Package{
+		Name:          name,
+		PkgVer:        plistString(dict, "pkgver"),
+		ShortDesc:     plistString(dict, "short_desc"),
+		Architecture:  plistString(dict, "architecture"),
+		Repository:    plistString(dict, "repository"),
+		Homepage:      plistString(dict, "homepage"),
+		License:       plistString(dict, "license"),
+		Maintainer:    plistString(dict, "maintainer"),
+		InstallDate:   plistString(dict, "install-date"),
+		State:         plistString(dict, "state"),
+		Automatic:     plistBool(dict, "automatic-install"),
+		Hold:          plistBool(dict, "hold"),
+		RepoLock:      plistBool(dict, "repolock"),
+		InstalledSize: plistInt(dict, "installed_size"),
+		FilenameSize:  plistInt(dict, "filename-size"),
+		RunDepends:    plistStrings(dict, "run_depends"),
+		Provides:      plistStrings(dict, "provides"),
+		ShlibProvides: plistStrings(dict, "shlib-provides"),
+		ShlibRequires: plistStrings(dict, "shlib-requires")}
+	pkg.Version = strings.TrimPrefix(pkg.PkgVer, name+"-")
+	for _, path := range plistStrings(dict, "conf_files") {
+		pkg.ConfFiles = append(pkg.ConfFiles, PkgFile{Path: path})
+	}
+	if alts, ok := dict["alternatives"].(map[string]interface{}); ok {
+		pkg.Alternatives = map[string][]string{}
+		for group := range alts {
+			pkg.Alternatives[group] = plistStrings(alts, group)
+		}
+	}
+	return pkg
+}
+
+// Adds the hashes of configuration files from the files metadata. Only
+// packages listing configuration files in the pkgdb are loaded.
+func (db *PkgDB) loadConfFiles(pkg *Package) error {
+	if len(pkg.ConfFiles) == 0 {
+		return nil
+	}
+	files, err := db.Files(pkg.Name)
+	if err != nil {
+		return err
+	}
+	hashes := map[string]string{}
+	for _, file := range files.ConfFiles {
+		hashes[file.Path] = file.SHA256
+	}
+	for idx, file := range pkg.ConfFiles {
+		pkg.ConfFiles[idx].SHA256 = hashes[file.Path]
+	}
+	return nil
+}
+
+// Sorted list of installed package names
+func (db *PkgDB) Names() []string {
+	names := make([]string, 0, len(db.Packages))
+	for name := range db.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Loads the files metadata for the named package
+func (db *PkgDB) Files(name string) (*PkgFiles, error) {
+	if _, ok := db.Packages[name]; !ok {
+		return nil, fmt.Errorf("Package `%s` is not installed", name)
+	}
+	path := filepath.Join(db.RootDir, XBPS_DB_PATH, "."+name+"-files.plist")
+	content, err := ParsePlistFile(path)
+	if err != nil {
+		// Meta packages do not ship any files
+		if os.IsNotExist(err) {
+			return &PkgFiles{}, nil
+		}
+		return nil, err
+	}
+	return &PkgFiles{
+		Files:     pkgFileList(content, "files"),
+		ConfFiles: pkgFileList(content, "conf_files"),
+		Links:     pkgFileList(content, "links"),
+		Dirs:      pkgFileList(content, "dirs")}, nil
+}
+
+// Searches the files metadata of all installed packages for paths
+// accepted by the match function
+func (db *PkgDB) FindFiles(match func(string) bool) ([]PkgFileMatch, error) {
+	matches := []PkgFileMatch{}
+	for _, name := range db.Names() {
+		files, err := db.Files(name)
+		if err != nil {
+			return matches, err
+		}
+		lists := []struct {
+			kind  string
+			files []PkgFile
+		}{
+			{"file", files.Files},
+			{"conf_file", files.ConfFiles},
+			{"link", files.Links},
+			{"dir", files.Dirs}}
+		for _, list := range lists {
+			for _, file := range list.files {
+				if match(file.Path) {
+					matches = append(matches, PkgFileMatch{
+						Package: name,
+						PkgVer:  db.Packages[name].PkgVer,
+						File:    file.Path,
+						Target:  file.Target,
+						Type:    list.kind,
+						Source:  "installed"})
+				}
+			}
+		}
+	}
+	return matches, nil
+}
+
+func pkgFileList(content map[string]interface{}, key string) []PkgFile {
+	files := []PkgFile{}
+	for _, dict := range plistDicts(content, key) {
+		files = append(files, PkgFile{
+			Path:   plistString(dict, "file"),
+			SHA256: plistString(dict, "sha256"),
+			Target: plistString(dict, "target"),
+			Size:   plistInt(dict, "size")})
+	}
+	return files
+}
+
+// Splits a pkgver string (`foo-1.2_1`) into name and version
+func splitPkgVer(pkgver string) (string, string, error) {
+	idx := strings.LastIndex(pkgver, "-")
+	if idx < 1 || idx == len(pkgver)-1 {
+		return "", "", fmt.Errorf("Invalid package version `%s`", pkgver)
+	}
+	return pkgver[:idx], pkgver[idx+1:], nil
+}
+
+// Package name referenced by a dependency pattern. Patterns are
+// either version constraints (`foo>=1.2_1`) or pkgvers (`foo-1.2_1`).
+func depPatternName(pattern string) string {
+	if idx := strings.IndexAny(pattern, "<>="); idx != -1 {
+		return pattern[:idx]
+	}
+	// Globs may contain dashes themselves (`foo-[0-9]*`)
+	base := pattern
+	if idx := strings.IndexAny(pattern, "[*?"); idx != -1 {
+		base = pattern[:idx]
+	}
+	if idx := strings.LastIndex(base, "-"); idx > 0 && idx < len(pattern)-1 {
+		if strings.IndexAny(pattern[idx+1:idx+2], "0123456789[*?") == 0 {
+			return pattern[:idx]
+		}
+	}
+	return pattern
+}
+
+// Name of the installed package satisfying a dependency, resolving
+// virtual packages through the provides of installed packages.
+func (db *PkgDB) Resolve(pattern string) (string, bool) {
+	name := depPatternName(pattern)
+	if _, ok := db.Packages[name]; ok {
+		return name, true
+	}
+	provider, ok := db.virtuals[name]
+	return provider, ok
+}
+
+// Maps each installed package to the installed packages depending on it
+func (db *PkgDB) ReverseDepends() map[string][]string {
+	revDeps := map[string][]string{}
+	for _, name := range db.Names() {
+		for _, dep := range db.Packages[name].RunDepends {
+			if target, ok := db.Resolve(dep); ok && target != name {
+				revDeps[target] = append(revDeps[target], name)
+			}
+		}
+	}
+	return revDeps
+}
+
+// Automatically installed packages no longer required by any package
+// other than other orphans.
+func (db *PkgDB) Orphans() []string {
+	revDeps := db.ReverseDepends()
+	orphans := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, name := range db.Names() {
+			if orphans[name] || !db.Packages[name].Automatic {
+				continue
+			}
+			required := false
+			for _, revDep := range revDeps[name] {
+				if !orphans[revDep] {
+					required = true
+					break
+				}
+			}
+			if !required {
+				orphans[name] = true
+				changed = true
+			}
+		}
+	}
+	names := []string{}
+	for _, name := range db.Names() {
+		if orphans[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Automatically installed dependencies which would be orphaned by
+// removing the given package, i.e. those only required by the package
+// itself or by other exclusive dependencies.
+func (db *PkgDB) ExclusiveDepends(name string, revDeps map[string][]string) []string {
+	removed := map[string]bool{name: true}
+	candidates := []string{name}
+	for len(candidates) > 0 {
+		current := candidates[0]
+		candidates = candidates[1:]
+		for _, dep := range db.Packages[current].RunDepends {
+			target, ok := db.Resolve(dep)
+			if !ok || removed[target] || !db.Packages[target].Automatic {
+				continue
+			}
+			required := false
+			for _, revDep := range revDeps[target] {
+				if !removed[revDep] {
+					required = true
+					break
+				}
+			}
+			if !required {
+				removed[target] = true
+				candidates = append(candidates, target)
+			}
+		}
+	}
+	names := []string{}
+	for _, dep := range db.Names() {
+		if removed[dep] && dep != name {
+			names = append(names, dep)
+		}
+	}
+	return names
+}
+
+// Hex encoded sha256 digest of the file at the given path
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
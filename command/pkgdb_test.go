@@ -0,0 +1,158 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const fixtureRoot = "testdata/root"
+
+func loadFixturePkgDB(t *testing.T) *PkgDB {
+	db, err := LoadPkgDB(fixtureRoot)
+	if err != nil {
+		t.Fatalf("LoadPkgDB() failed: %s", err)
+	}
+	return db
+}
+
+func TestLoadPkgDB(t *testing.T) {
+	db := loadFixturePkgDB(t)
+	names := db.Names()
+	expected := []string{"base-files", "foo", "libbar"}
+	if len(names) != len(expected) {
+		t.Fatalf("Names() = %v, expected %v", names, expected)
+	}
+	for idx, name := range expected {
+		if names[idx] != name {
+			t.Errorf("Names()[%d] = %s, expected %s", idx, names[idx], name)
+		}
+	}
+	foo := db.Packages["foo"]
+	if foo.Version != "1.0_1" || foo.PkgVer != "foo-1.0_1" {
+		t.Errorf("foo version = %s (%s), expected 1.0_1", foo.Version, foo.PkgVer)
+	}
+	if foo.InstalledSize != 2048 {
+		t.Errorf("foo installed size = %d, expected 2048", foo.InstalledSize)
+	}
+	if len(foo.RunDepends) != 1 || foo.RunDepends[0] != "libbar>=1.0_1" {
+		t.Errorf("foo run depends = %v", foo.RunDepends)
+	}
+	if _, err := LoadPkgDB("testdata/missing"); err == nil {
+		t.Errorf("LoadPkgDB() of missing root succeeded")
+	}
+}
+
+func TestLoadPkgDBFormat(t *testing.T) {
+	root, err := ioutil.TempDir("", "void-pkgdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	dbDir := filepath.Join(root, XBPS_DB_PATH)
+	if err := os.MkdirAll(dbDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writePkgDB := func(name string, pkgName string) {
+		content := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>` + pkgName + `</key>
+	<dict>
+		<key>conf_files</key>
+		<array>
+			<string>/etc/` + pkgName + `.conf</string>
+		</array>
+		<key>pkgver</key>
+		<string>` + pkgName + `-1.0_1</string>
+	</dict>
+</dict>
+</plist>
+`
+		if err := ioutil.WriteFile(filepath.Join(dbDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		file     string
+		expected string
+	}{
+		{"pkgdb-0.9.plist", "pkgdb-0.9.plist"},
+		{"pkgdb-0.10.plist", "pkgdb-0.10.plist"},
+		{XBPS_PKGDB_FILE, XBPS_PKGDB_FILE},
+		{"pkgdb-0.40.plist", XBPS_PKGDB_FILE},
+	}
+	for _, tc := range cases {
+		writePkgDB(tc.file, "foo")
+		db, err := LoadPkgDB(root)
+		if err != nil {
+			t.Fatalf("LoadPkgDB() failed: %s", err)
+		}
+		if filepath.Base(db.Path) != tc.expected {
+			t.Errorf("LoadPkgDB() with %s loaded %s, expected %s", tc.file, db.Path, tc.expected)
+		}
+	}
+	// Unreadable files metadata only affects configuration file hashes
+	if err := ioutil.WriteFile(filepath.Join(dbDir, ".foo-files.plist"), []byte("<plist"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := LoadPkgDB(root)
+	if err != nil {
+		t.Fatalf("LoadPkgDB() with broken files metadata failed: %s", err)
+	}
+	if len(db.warnings) != 1 || db.Packages["foo"].ConfFiles[0].SHA256 != "" {
+		t.Errorf("LoadPkgDB() warnings = %v", db.warnings)
+	}
+}
+
+func TestPkgDBModes(t *testing.T) {
+	db := loadFixturePkgDB(t)
+	cases := []struct {
+		name      string
+		automatic bool
+		hold      bool
+		repolock  bool
+	}{
+		{"base-files", false, false, false},
+		{"foo", true, true, false},
+		{"libbar", true, false, true},
+	}
+	for _, tc := range cases {
+		pkg := db.Packages[tc.name]
+		if pkg.Automatic != tc.automatic || pkg.Hold != tc.hold || pkg.RepoLock != tc.repolock {
+			t.Errorf("%s automatic/hold/repolock = %t/%t/%t, expected %t/%t/%t", tc.name,
+				pkg.Automatic, pkg.Hold, pkg.RepoLock, tc.automatic, tc.hold, tc.repolock)
+		}
+	}
+}
+
+func TestPkgDBConfFiles(t *testing.T) {
+	db := loadFixturePkgDB(t)
+	conf := db.Packages["base-files"].ConfFiles
+	if len(conf) != 1 || conf[0].Path != "/etc/hosts" {
+		t.Fatalf("base-files conf files = %v", conf)
+	}
+	if conf[0].SHA256 != "b1946ac92492d2347c6235b4d2611184b1946ac92492d2347c6235b4d2611184" {
+		t.Errorf("/etc/hosts hash = %s", conf[0].SHA256)
+	}
+	if len(db.Packages["foo"].ConfFiles) != 0 {
+		t.Errorf("foo conf files = %v, expected none", db.Packages["foo"].ConfFiles)
+	}
+}
+
+func TestLoadHoldNotes(t *testing.T) {
+	notes, err := LoadHoldNotes(fixtureRoot)
+	if err != nil {
+		t.Fatalf("LoadHoldNotes() failed: %s", err)
+	}
+	if note := notes["foo"]; note.Reason != "waiting on upstream fix" || note.Version != "1.0_1" {
+		t.Errorf("foo hold note = %+v", note)
+	}
+	if _, ok := notes["libbar"]; ok {
+		t.Errorf("Unexpected hold note for libbar")
+	}
+	if notes, err := LoadHoldNotes("testdata/missing"); err != nil || len(notes) != 0 {
+		t.Errorf("LoadHoldNotes() of missing root = %v, %v", notes, err)
+	}
+}
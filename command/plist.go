@@ -0,0 +1,180 @@
+package command
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decodes an XML property list as written by xbps. Dictionaries are
+// returned as map[string]interface{}, arrays as []interface{} and
+// scalars as string, int64, float64, bool, []byte or time.Time.
+func ParsePlist(r io.Reader) (interface{}, error) {
+	decoder := xml.NewDecoder(r)
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("No property list content found")
+			}
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local == "plist" {
+				continue
+			}
+			return decodePlistValue(decoder, start)
+		}
+	}
+}
+
+// Decodes the property list file at the given path. The root
+// element is expected to be a dictionary.
+func ParsePlistFile(path string) (map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	value, err := ParsePlist(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	dict, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: root element is not a dictionary", path)
+	}
+	return dict, nil
+}
+
+func decodePlistValue(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := map[string]interface{}{}
+		key := ""
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if key, err = plistText(d); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				value, err := decodePlistValue(d, t)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = value
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+	case "array":
+		list := []interface{}{}
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				value, err := decodePlistValue(d, t)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			case xml.EndElement:
+				return list, nil
+			}
+		}
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+	text, err := plistText(d)
+	if err != nil {
+		return nil, err
+	}
+	switch start.Name.Local {
+	case "string":
+		return text, nil
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(text), 0, 64)
+	case "real":
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	case "data":
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	case "date":
+		return time.Parse(time.RFC3339, strings.TrimSpace(text))
+	}
+	return nil, fmt.Errorf("Unsupported property list element `%s`", start.Name.Local)
+}
+
+// Collects character data up to the end of the current element
+func plistText(d *xml.Decoder) (string, error) {
+	text := ""
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text = text + string(t)
+		case xml.StartElement:
+			return "", fmt.Errorf("Unexpected element `%s` in text value", t.Name.Local)
+		case xml.EndElement:
+			return text, nil
+		}
+	}
+}
+
+func plistString(dict map[string]interface{}, key string) string {
+	value, _ := dict[key].(string)
+	return value
+}
+
+func plistBool(dict map[string]interface{}, key string) bool {
+	value, _ := dict[key].(bool)
+	return value
+}
+
+func plistInt(dict map[string]interface{}, key string) int64 {
+	value, _ := dict[key].(int64)
+	return value
+}
+
+func plistStrings(dict map[string]interface{}, key string) []string {
+	list, _ := dict[key].([]interface{})
+	values := []string{}
+	for _, item := range list {
+		if value, ok := item.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func plistDicts(dict map[string]interface{}, key string) []map[string]interface{} {
+	list, _ := dict[key].([]interface{})
+	values := []map[string]interface{}{}
+	for _, item := range list {
+		if value, ok := item.(map[string]interface{}); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,173 @@
+package command
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const PROC_PATH = "/proc"
+const DELETED_SUFFIX = " (deleted)"
+
+// Mapped paths which are expected to be deleted and do not indicate
+// an outdated binary or library
+var ignoredDeletedPrefixes = []string{"/dev/", "/memfd:", "/tmp/", "/run/", "/var/", "/SYSV", "/home/", "/[aio]"}
+
+// Running process using files which have been removed or replaced
+type StaleProcess struct {
+	Pid     int      `json:"pid"`
+	Ppid    int      `json:"ppid"`
+	Command string   `json:"command"`
+	Service string   `json:"service,omitempty"`
+	Files   []string `json:"files"`
+}
+
+// Scans the process table for processes whose executable or mapped
+// files have been deleted. Processes which cannot be inspected (other
+// users when not running as root) are skipped.
+func scanStaleProcesses(procPath string) ([]*StaleProcess, error) {
+	entries, err := ioutil.ReadDir(procPath)
+	if err != nil {
+		return nil, err
+	}
+	procs := []*StaleProcess{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join(procPath, entry.Name())
+		files := map[string]bool{}
+		if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+			if path, ok := deletedPath(exe); ok {
+				files[path] = true
+			}
+		}
+		for _, path := range deletedMappings(filepath.Join(dir, "maps")) {
+			files[path] = true
+		}
+		if len(files) == 0 {
+			continue
+		}
+		proc := &StaleProcess{Pid: pid, Files: []string{}}
+		proc.Command, proc.Ppid = procStat(dir)
+		for path := range files {
+			proc.Files = append(proc.Files, path)
+		}
+		sort.Strings(proc.Files)
+		procs = append(procs, proc)
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].Pid < procs[j].Pid })
+	return procs, nil
+}
+
+// Deleted file paths referenced by a process memory map
+func deletedMappings(path string) []string {
+	paths := []string{}
+	file, err := os.Open(path)
+	if err != nil {
+		return paths
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// address perms offset dev inode pathname
+		fields := strings.SplitN(scanner.Text(), " ", 6)
+		if len(fields) < 6 {
+			continue
+		}
+		if path, ok := deletedPath(strings.TrimSpace(fields[5])); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func deletedPath(path string) (string, bool) {
+	if !strings.HasSuffix(path, DELETED_SUFFIX) {
+		return "", false
+	}
+	path = strings.TrimSuffix(path, DELETED_SUFFIX)
+	for _, prefix := range ignoredDeletedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return "", false
+		}
+	}
+	return path, strings.HasPrefix(path, "/")
+}
+
+// Command name and parent pid from `/proc/PID/stat`. The command is
+// enclosed in parentheses and may itself contain spaces.
+func procStat(dir string) (string, int) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return "", 0
+	}
+	stat := string(content)
+	start := strings.Index(stat, "(")
+	end := strings.LastIndex(stat, ")")
+	if start == -1 || end < start {
+		return "", 0
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 2 {
+		return stat[start+1 : end], 0
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	return stat[start+1 : end], ppid
+}
+
+// Parent pid of every process, used to attribute children to services
+func procParents(procPath string) map[int]int {
+	parents := map[int]int{}
+	entries, err := ioutil.ReadDir(procPath)
+	if err != nil {
+		return parents
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		_, parents[pid] = procStat(filepath.Join(procPath, entry.Name()))
+	}
+	return parents
+}
+
+// Main pids of runit services and their log services read from their
+// `supervise/pid` files
+func supervisedPids(serviceDir string) map[int]string {
+	pids := map[int]string{}
+	paths, _ := filepath.Glob(filepath.Join(serviceDir, "*", "supervise", "pid"))
+	logPaths, _ := filepath.Glob(filepath.Join(serviceDir, "*", "log", "supervise", "pid"))
+	for _, path := range append(paths, logPaths...) {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// Log services are named `service/log` which sv accepts
+		service, _ := filepath.Rel(serviceDir, filepath.Dir(filepath.Dir(path)))
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(content))); err == nil && pid > 0 {
+			pids[pid] = service
+		}
+	}
+	return pids
+}
+
+// Service owning a process, found by walking up its parents until a
+// supervised service pid is reached
+func processService(pid int, parents map[int]int, services map[int]string) string {
+	seen := map[int]bool{}
+	for pid > 1 && !seen[pid] {
+		if service, ok := services[pid]; ok {
+			return service
+		}
+		seen[pid] = true
+		pid = parents[pid]
+	}
+	return ""
+}
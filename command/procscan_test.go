@@ -0,0 +1,37 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSupervisedPids(t *testing.T) {
+	root, err := ioutil.TempDir("", "void-procscan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	pids := map[string]string{
+		"sshd/supervise/pid":     "100\n",
+		"sshd/log/supervise/pid": "101\n",
+		"dhcpcd/supervise/pid":   "\n"}
+	for path, content := range pids {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	services := supervisedPids(root)
+	if len(services) != 2 || services[100] != "sshd" || services[101] != "sshd/log" {
+		t.Errorf("supervisedPids() = %v", services)
+	}
+	parents := map[int]int{102: 101, 101: 50, 50: 1}
+	if service := processService(102, parents, services); service != "sshd/log" {
+		t.Errorf("processService() of log child = %q, expected sshd/log", service)
+	}
+}
@@ -0,0 +1,262 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const RC_CONF_PATH = "/etc/rc.conf"
+const ZONEINFO_PATH = "/usr/share/zoneinfo"
+const KBD_KEYMAPS_PATH = "/usr/share/kbd/keymaps"
+const KBD_CONSOLEFONTS_PATH = "/usr/share/kbd/consolefonts"
+
+var rcConfAssignment = regexp.MustCompile(`^(\s*#\s*)?([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+var shellVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`)
+
+// Shell variable assignment in rc.conf. Commented assignments are the
+// documented defaults shipped with the file.
+type RcConfEntry struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Line      int    `json:"line"`
+	Commented bool   `json:"commented"`
+}
+
+// The rc.conf file kept as lines so comments and ordering survive edits
+type RcConf struct {
+	Path  string
+	Lines []string
+	Mode  os.FileMode
+}
+
+// Loads rc.conf below the given root. A missing file loads empty.
+func LoadRcConf(rootDir string) (*RcConf, error) {
+	conf := &RcConf{Path: filepath.Join(rootDir, RC_CONF_PATH), Lines: []string{}, Mode: 0644}
+	content, err := ioutil.ReadFile(conf.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return conf, nil
+		}
+		return nil, err
+	}
+	if info, err := os.Stat(conf.Path); err == nil {
+		conf.Mode = info.Mode().Perm()
+	}
+	conf.Lines = splitLines(string(content))
+	return conf, nil
+}
+
+func (r *RcConf) Entries() []RcConfEntry {
+	entries := []RcConfEntry{}
+	for idx, line := range r.Lines {
+		match := rcConfAssignment.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		entries = append(entries, RcConfEntry{
+			Key:       match[2],
+			Value:     unquoteShellValue(match[3]),
+			Line:      idx + 1,
+			Commented: match[1] != ""})
+	}
+	return entries
+}
+
+// Active value of a key. The last assignment wins as in the shell.
+func (r *RcConf) Get(key string) (string, bool) {
+	value, found := "", false
+	for _, entry := range r.Entries() {
+		if entry.Key == key && !entry.Commented {
+			value, found = entry.Value, true
+		}
+	}
+	return value, found
+}
+
+// Sets a key, replacing active assignments in place. New keys are
+// placed after their commented default when one exists, otherwise
+// appended to the file.
+func (r *RcConf) Set(key string, value string) {
+	line := key + "=" + quoteShellValue(value)
+	replaced := false
+	commented := -1
+	lines := []string{}
+	for _, entry := range r.Entries() {
+		if entry.Key != key {
+			continue
+		}
+		if entry.Commented {
+			commented = entry.Line - 1
+		}
+	}
+	for idx, current := range r.Lines {
+		match := rcConfAssignment.FindStringSubmatch(current)
+		if match != nil && match[2] == key && match[1] == "" {
+			if !replaced {
+				lines = append(lines, line+trailingShellComment(match[3]))
+				replaced = true
+			}
+			continue
+		}
+		lines = append(lines, current)
+		if !replaced && idx == commented && !r.hasActive(key) {
+			lines = append(lines, line)
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, line)
+	}
+	r.Lines = lines
+}
+
+// Removes active assignments of a key. Commenting them out instead
+// would make them look like shipped defaults.
+func (r *RcConf) Unset(key string) bool {
+	found := false
+	lines := []string{}
+	for _, current := range r.Lines {
+		match := rcConfAssignment.FindStringSubmatch(current)
+		if match != nil && match[2] == key && match[1] == "" {
+			found = true
+			continue
+		}
+		lines = append(lines, current)
+	}
+	r.Lines = lines
+	return found
+}
+
+func (r *RcConf) hasActive(key string) bool {
+	_, found := r.Get(key)
+	return found
+}
+
+func (r *RcConf) Save() error {
+	content := strings.Join(r.Lines, "\n")
+	if len(r.Lines) > 0 {
+		content = content + "\n"
+	}
+	return writeFileAtomic(r.Path, []byte(content), r.Mode)
+}
+
+// Strips shell quoting and trailing comments from an assignment value
+func unquoteShellValue(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	switch raw[0] {
+	case '"':
+		value := []byte{}
+		for i := 1; i < len(raw); i++ {
+			if raw[i] == '\\' && i+1 < len(raw) && strings.IndexByte("\"\\$`", raw[i+1]) != -1 {
+				i++
+			} else if raw[i] == '"' {
+				break
+			}
+			value = append(value, raw[i])
+		}
+		return string(value)
+	case '\'':
+		if end := strings.Index(raw[1:], "'"); end != -1 {
+			return raw[1 : end+1]
+		}
+		return raw[1:]
+	}
+	if idx := strings.Index(raw, " #"); idx != -1 {
+		raw = raw[:idx]
+	}
+	return strings.TrimSpace(raw)
+}
+
+// Comment following an assignment value, including its leading space
+func trailingShellComment(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	rest := ""
+	switch {
+	case strings.HasPrefix(trimmed, `"`):
+		for i := 1; i < len(trimmed); i++ {
+			if trimmed[i] == '\\' {
+				i++
+			} else if trimmed[i] == '"' {
+				rest = trimmed[i+1:]
+				break
+			}
+		}
+	case strings.HasPrefix(trimmed, "'"):
+		if end := strings.Index(trimmed[1:], "'"); end != -1 {
+			rest = trimmed[end+2:]
+		}
+	default:
+		if idx := strings.Index(trimmed, " #"); idx != -1 {
+			rest = trimmed[idx:]
+		}
+	}
+	if rest = strings.TrimSpace(rest); strings.HasPrefix(rest, "#") {
+		return " " + rest
+	}
+	return ""
+}
+
+func quoteShellValue(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(value)
+	return `"` + escaped + `"`
+}
+
+// Validates well known rc.conf settings against the system below the
+// given root. Unknown keys only need to be valid shell variable names.
+func validateRcConfValue(rootDir string, key string, value string) error {
+	if !shellVariableName.MatchString(key) {
+		return fmt.Errorf("Invalid variable name `%s`", key)
+	}
+	if strings.Contains(value, "\n") {
+		return errors.New("Values cannot contain newlines")
+	}
+	switch key {
+	case "HOSTNAME":
+		return validateHostname(value)
+	case "TIMEZONE":
+		return validateTimezone(rootDir, value)
+	case "HARDWARECLOCK":
+		if value != "UTC" && value != "localtime" {
+			return fmt.Errorf("Invalid hardware clock `%s` (expected `UTC` or `localtime`)", value)
+		}
+	case "KEYMAP":
+		matches, _ := filepath.Glob(filepath.Join(rootDir, KBD_KEYMAPS_PATH, "*", "*", value+".map*"))
+		more, _ := filepath.Glob(filepath.Join(rootDir, KBD_KEYMAPS_PATH, "*", value+".map*"))
+		if len(matches)+len(more) == 0 {
+			return fmt.Errorf("Unknown keymap `%s`", value)
+		}
+	case "FONT":
+		matches, _ := filepath.Glob(filepath.Join(rootDir, KBD_CONSOLEFONTS_PATH, value+".*"))
+		if len(matches) == 0 {
+			return fmt.Errorf("Unknown console font `%s`", value)
+		}
+	}
+	return nil
+}
+
+func validateHostname(value string) error {
+	if len(value) > 253 || !hostnamePattern.MatchString(value) {
+		return fmt.Errorf("Invalid hostname `%s`", value)
+	}
+	return nil
+}
+
+func validateTimezone(rootDir string, value string) error {
+	if value == "" || strings.Contains(value, "..") || filepath.IsAbs(value) {
+		return fmt.Errorf("Invalid timezone `%s`", value)
+	}
+	info, err := os.Stat(filepath.Join(rootDir, ZONEINFO_PATH, value))
+	if err != nil || !info.Mode().IsRegular() {
+		return fmt.Errorf("Unknown timezone `%s`", value)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testRcConf() *RcConf {
+	return &RcConf{Lines: []string{
+		"# Default settings",
+		`# HOSTNAME="void"`,
+		`#TIMEZONE="Europe/Madrid"`,
+		`KEYMAP="us" # console keymap`,
+		"FONT=lat9w-16",
+		`FONT="ter-v16n"`}}
+}
+
+func TestRcConfSet(t *testing.T) {
+	cases := []struct {
+		key      string
+		value    string
+		expected []string
+	}{
+		{"KEYMAP", "de", []string{
+			"# Default settings",
+			`# HOSTNAME="void"`,
+			`#TIMEZONE="Europe/Madrid"`,
+			`KEYMAP="de" # console keymap`,
+			"FONT=lat9w-16",
+			`FONT="ter-v16n"`}},
+		{"HOSTNAME", "box", []string{
+			"# Default settings",
+			`# HOSTNAME="void"`,
+			`HOSTNAME="box"`,
+			`#TIMEZONE="Europe/Madrid"`,
+			`KEYMAP="us" # console keymap`,
+			"FONT=lat9w-16",
+			`FONT="ter-v16n"`}},
+		{"FONT", "ter-v32n", []string{
+			"# Default settings",
+			`# HOSTNAME="void"`,
+			`#TIMEZONE="Europe/Madrid"`,
+			`KEYMAP="us" # console keymap`,
+			`FONT="ter-v32n"`}},
+		{"MODULES", `a "b" $c`, []string{
+			"# Default settings",
+			`# HOSTNAME="void"`,
+			`#TIMEZONE="Europe/Madrid"`,
+			`KEYMAP="us" # console keymap`,
+			"FONT=lat9w-16",
+			`FONT="ter-v16n"`,
+			`MODULES="a \"b\" \$c"`}},
+	}
+	for _, tc := range cases {
+		conf := testRcConf()
+		conf.Set(tc.key, tc.value)
+		if !reflect.DeepEqual(conf.Lines, tc.expected) {
+			t.Errorf("Set(%q, %q) lines = %q", tc.key, tc.value, conf.Lines)
+		}
+		if value, ok := conf.Get(tc.key); !ok || value != tc.value {
+			t.Errorf("Get(%q) after Set = %q, %t", tc.key, value, ok)
+		}
+	}
+}
+
+func TestRcConfUnset(t *testing.T) {
+	conf := testRcConf()
+	if !conf.Unset("FONT") {
+		t.Errorf("Unset(FONT) = false, expected true")
+	}
+	expected := []string{
+		"# Default settings",
+		`# HOSTNAME="void"`,
+		`#TIMEZONE="Europe/Madrid"`,
+		`KEYMAP="us" # console keymap`}
+	if !reflect.DeepEqual(conf.Lines, expected) {
+		t.Errorf("Unset(FONT) lines = %q", conf.Lines)
+	}
+	if conf.Unset("HOSTNAME") {
+		t.Errorf("Unset(HOSTNAME) = true for commented default")
+	}
+	if !reflect.DeepEqual(conf.Lines, expected) {
+		t.Errorf("Unset(HOSTNAME) changed lines: %q", conf.Lines)
+	}
+	if _, ok := conf.Get("FONT"); ok {
+		t.Errorf("Get(FONT) found a value after Unset")
+	}
+}
+
+func TestUnquoteShellValue(t *testing.T) {
+	cases := map[string]string{
+		``:                     "",
+		`us`:                   "us",
+		`us # keymap`:          "us",
+		`"Europe/Madrid"`:      "Europe/Madrid",
+		`"a \"b\" \$c \\ \x"`:  `a "b" $c \ \x`,
+		`"a # b" # comment`:    "a # b",
+		`'a "b" $c' # comment`: `a "b" $c`,
+		`'unterminated`:        "unterminated",
+		`  spaced  `:           "spaced",
+	}
+	for raw, expected := range cases {
+		if value := unquoteShellValue(raw); value != expected {
+			t.Errorf("unquoteShellValue(%q) = %q, expected %q", raw, value, expected)
+		}
+	}
+}
+
+func TestTrailingShellComment(t *testing.T) {
+	cases := map[string]string{
+		`us`:                "",
+		`us # keymap`:       " # keymap",
+		`"us"   #keymap`:    " #keymap",
+		`"a # b"`:           "",
+		`"a \" # b" # real`: " # real",
+		`'a # b' # real`:    " # real",
+		`"us" trailing`:     "",
+	}
+	for raw, expected := range cases {
+		if comment := trailingShellComment(raw); comment != expected {
+			t.Errorf("trailingShellComment(%q) = %q, expected %q", raw, comment, expected)
+		}
+	}
+}
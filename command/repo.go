@@ -0,0 +1,321 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mitchellh/cli"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const VOID_REPO_URL = "https://repo-default.voidlinux.org/current"
+
+// Configuration file and path suffix of well known repositories
+type knownRepo struct {
+	File   string
+	Suffix string
+}
+
+var knownRepos = map[string]knownRepo{
+	"main":             knownRepo{File: "00-repository-main.conf", Suffix: ""},
+	"nonfree":          knownRepo{File: "10-repository-nonfree.conf", Suffix: "/nonfree"},
+	"multilib":         knownRepo{File: "10-repository-multilib.conf", Suffix: "/multilib"},
+	"multilib-nonfree": knownRepo{File: "10-repository-multilib-nonfree.conf", Suffix: "/multilib/nonfree"},
+	"debug":            knownRepo{File: "20-repository-debug.conf", Suffix: "/debug"}}
+
+// Repository command stub
+type RepoCommand struct {
+	PkgCommand
+	Repo string
+}
+
+func (c *RepoCommand) Commands(appName string, ui cli.Ui, debug bool) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"repo add": func() (cli.Command, error) {
+			return &RepoAddCommand{
+				RepoCommand: RepoCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void repo add URL|PATH|nonfree|multilib|multilib-nonfree|debug|local",
+							SynopsisText: "Add a repository",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "name",
+									Boolean:     false,
+									Description: "Name used for the configuration file"},
+								CoreFlag{
+									Name:        "priority",
+									Boolean:     false,
+									Description: "Configuration file priority prefix (00-99)"},
+								CoreFlag{
+									Name:        "path",
+									Boolean:     false,
+									Description: "Path of the local repository",
+									Default:     "hostdir/binpkgs"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"repo disable": func() (cli.Command, error) {
+			return &RepoDisableCommand{
+				RepoCommand: RepoCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void repo disable URL|NAME",
+							SynopsisText: "Disable a repository",
+							Flags:        c.flags(),
+							UI:           ui,
+							AppName:      appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"repo enable": func() (cli.Command, error) {
+			return &RepoEnableCommand{
+				RepoCommand: RepoCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void repo enable URL|NAME",
+							SynopsisText: "Enable a disabled repository",
+							Flags:        c.flags(),
+							UI:           ui,
+							AppName:      appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"repo list": func() (cli.Command, error) {
+			return &RepoListCommand{
+				RepoCommand: RepoCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void repo list",
+							SynopsisText: "List configured repositories",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "json",
+									Boolean:     true,
+									Description: "Output as JSON"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"repo mirror list": func() (cli.Command, error) {
+			return &RepoMirrorListCommand{
+				RepoCommand: RepoCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void repo mirror list",
+							SynopsisText: "List available repository mirrors",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "file",
+									Boolean:     false,
+									Description: "Mirror list file (one `URL [region]` per line)"},
+								CoreFlag{
+									Name:        "rank",
+									Boolean:     true,
+									Description: "Rank mirrors by connect latency"},
+								CoreFlag{
+									Name:        "json",
+									Boolean:     true,
+									Description: "Output as JSON"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"repo mirror set": func() (cli.Command, error) {
+			return &RepoMirrorSetCommand{
+				RepoCommand: RepoCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void repo mirror set URL|--fastest",
+							SynopsisText: "Switch all repositories to a mirror",
+							Flags: c.flags(
+								CoreFlag{
+									Name:        "file",
+									Boolean:     false,
+									Description: "Mirror list file (one `URL [region]` per line)"},
+								CoreFlag{
+									Name:        "fastest",
+									Boolean:     true,
+									Description: "Use the mirror with the lowest connect latency"}),
+							UI:      ui,
+							AppName: appName,
+						},
+					},
+				},
+			}, nil
+		},
+		"repo remove": func() (cli.Command, error) {
+			return &RepoRemoveCommand{
+				RepoCommand: RepoCommand{
+					PkgCommand: PkgCommand{
+						CoreCommand: CoreCommand{
+							Debug:        debug,
+							HelpText:     "void repo remove URL|NAME",
+							SynopsisText: "Remove a repository",
+							Flags:        c.flags(),
+							UI:           ui,
+							AppName:      appName,
+						},
+					},
+				},
+			}, nil
+		},
+	}
+}
+
+func (c *RepoCommand) Init(args []string, repo bool) (ParsedCli, error) {
+	fmtOpts, err := c.PkgCommand.Init(args, false)
+	if err != nil {
+		return fmtOpts, err
+	}
+	if repo {
+		if len(fmtOpts.Args) != 1 {
+			return fmtOpts, errors.New("Single repository required!")
+		} else {
+			c.Repo = fmtOpts.Args[0]
+		}
+	}
+	return fmtOpts, nil
+}
+
+// Mirror list from the file given by the `file` flag or the bundled list
+func (c *RepoCommand) mirrors(cOpts ParsedCli) ([]Mirror, error) {
+	path := ""
+	if flag := cOpts.Get("file"); flag != nil {
+		path = flag.Value
+	}
+	return LoadMirrors(path)
+}
+
+// Base URL of the main repository which well known repositories are
+// relative to
+func (c *RepoCommand) mainURL(config *XbpsConfig) string {
+	for _, entry := range config.RepositoryEntries() {
+		if filepath.Base(entry.File) == knownRepos["main"].File {
+			return strings.TrimRight(entry.Value, "/")
+		}
+	}
+	return VOID_REPO_URL
+}
+
+// Resolves a repository argument (well known name, local path or
+// URL) to the repository location and its configuration file name
+func (c *RepoCommand) resolveRepo(config *XbpsConfig, arg string, cOpts ParsedCli) (string, string, error) {
+	if known, ok := knownRepos[arg]; ok {
+		return c.mainURL(config) + known.Suffix, known.File, nil
+	}
+	location := arg
+	prefix := "50"
+	if arg == "local" {
+		location = cOpts.Get("path").Value
+		prefix = "00"
+	}
+	if strings.Contains(location, "://") {
+		if _, err := url.Parse(location); err != nil {
+			return "", "", err
+		}
+	} else {
+		abs, err := filepath.Abs(location)
+		if err != nil {
+			return "", "", err
+		}
+		location = abs
+		prefix = "00"
+	}
+	location = strings.TrimRight(location, "/")
+	name := ""
+	if flag := cOpts.Get("name"); flag != nil && flag.Value != "" {
+		name = flag.Value
+	} else {
+		name = repoSlug(location)
+	}
+	if flag := cOpts.Get("priority"); flag != nil && flag.Value != "" {
+		prefix = flag.Value
+	}
+	return location, fmt.Sprintf("%s-repository-%s.conf", prefix, name), nil
+}
+
+// Configuration entries (enabled or disabled) for the repository
+// named by the argument
+func (c *RepoCommand) findEntries(config *XbpsConfig, arg string) ([]XbpsConfEntry, error) {
+	location := strings.TrimRight(arg, "/")
+	if known, ok := knownRepos[arg]; ok {
+		location = c.mainURL(config) + known.Suffix
+	} else if !strings.Contains(location, "://") {
+		if abs, err := filepath.Abs(location); err == nil {
+			location = abs
+		}
+	}
+	entries := []XbpsConfEntry{}
+	for _, entry := range config.RepositoryEntries() {
+		if strings.TrimRight(entry.Value, "/") == location {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return entries, errors.New(fmt.Sprintf(
+			"Repository `%s` is not configured", location))
+	}
+	return entries, nil
+}
+
+// Rewrites the lines of the given entries using the edit function,
+// grouping edits per configuration file
+func (c *RepoCommand) editEntries(config *XbpsConfig, entries []XbpsConfEntry, edit func(line string) []string) ([]string, error) {
+	byFile := map[string]map[int]bool{}
+	files := []string{}
+	for _, entry := range entries {
+		if _, ok := byFile[entry.File]; !ok {
+			byFile[entry.File] = map[int]bool{}
+			files = append(files, entry.File)
+		}
+		byFile[entry.File][entry.Line] = true
+	}
+	written := []string{}
+	for _, file := range files {
+		dest, err := config.EditFile(file, func(lineNo int, line string) []string {
+			if byFile[file][lineNo] {
+				return edit(line)
+			}
+			return []string{line}
+		})
+		if err != nil {
+			return written, err
+		}
+		written = append(written, dest)
+	}
+	return written, nil
+}
+
+var repoSlugPattern = regexp.MustCompile("[^a-z0-9]+")
+
+// Configuration file name component derived from a repository location
+func repoSlug(location string) string {
+	location = strings.ToLower(location)
+	if idx := strings.Index(location, "://"); idx != -1 {
+		location = location[idx+3:]
+	}
+	return strings.Trim(repoSlugPattern.ReplaceAllString(location, "-"), "-")
+}
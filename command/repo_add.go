@@ -0,0 +1,67 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+type RepoAddCommand struct {
+	RepoCommand
+}
+
+func (c *RepoAddCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args, true)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup repository command: %s", err))
+		return exitCode
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	location, name, err := c.resolveRepo(config, c.Repo, cOpts)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Invalid repository `%s`: %s", c.Repo, err))
+		return exitCode
+	}
+	if entries, err := c.findEntries(config, location); err == nil {
+		for _, entry := range entries {
+			if !entry.Disabled {
+				c.UI.Error(fmt.Sprintf(
+					"Repository `%s` is already configured in %s", location, entry.File))
+				return exitCode
+			}
+		}
+		c.UI.Error(fmt.Sprintf(
+			"Repository `%s` is disabled, use `%s repo enable` instead", location, c.AppName))
+		return exitCode
+	}
+	lines := []string{}
+	if content, err := ioutil.ReadFile(config.SysconfPath(name)); err == nil {
+		lines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	} else if !os.IsNotExist(err) {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to read repository configuration: %s", err))
+		return exitCode
+	}
+	lines = append(lines, "repository="+location)
+	if err := config.writeConfFile(name, lines); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write repository configuration: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf(
+		"Added repository: %s (%s)", location, config.SysconfPath(name)))
+	return 0
+}
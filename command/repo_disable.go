@@ -0,0 +1,56 @@
+package command
+
+import (
+	"fmt"
+)
+
+type RepoDisableCommand struct {
+	RepoCommand
+}
+
+func (c *RepoDisableCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	_, err := c.Init(args, true)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup repository command: %s", err))
+		return exitCode
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	entries, err := c.findEntries(config, c.Repo)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	enabled := []XbpsConfEntry{}
+	for _, entry := range entries {
+		if !entry.Disabled {
+			enabled = append(enabled, entry)
+		}
+	}
+	if len(enabled) == 0 {
+		c.UI.Error(fmt.Sprintf(
+			"Repository `%s` is already disabled!", entries[0].Value))
+		return exitCode
+	}
+	_, err = c.editEntries(config, enabled, func(line string) []string {
+		return []string{"#" + line}
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write repository configuration: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf(
+		"Disabled repository: %s", enabled[0].Value))
+	return 0
+}
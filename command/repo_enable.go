@@ -0,0 +1,57 @@
+package command
+
+import (
+	"fmt"
+)
+
+type RepoEnableCommand struct {
+	RepoCommand
+}
+
+func (c *RepoEnableCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	_, err := c.Init(args, true)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup repository command: %s", err))
+		return exitCode
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	entries, err := c.findEntries(config, c.Repo)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"%s (use `%s repo add` to add it)", err, c.AppName))
+		return exitCode
+	}
+	disabled := []XbpsConfEntry{}
+	for _, entry := range entries {
+		if entry.Disabled {
+			disabled = append(disabled, entry)
+		}
+	}
+	if len(disabled) == 0 {
+		c.UI.Error(fmt.Sprintf(
+			"Repository `%s` is already enabled!", entries[0].Value))
+		return exitCode
+	}
+	_, err = c.editEntries(config, disabled, func(line string) []string {
+		return []string{disabledRepoLine(line)}
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write repository configuration: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf(
+		"Enabled repository: %s", disabled[0].Value))
+	return 0
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"fmt"
+)
+
+type RepoListCommand struct {
+	RepoCommand
+}
+
+// Repository entry of the effective configuration
+type RepoListEntry struct {
+	Position   int    `json:"position,omitempty"`
+	Repository string `json:"repository"`
+	Enabled    bool   `json:"enabled"`
+	File       string `json:"file"`
+	Overrides  string `json:"overrides,omitempty"`
+}
+
+func (c *RepoListCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup repository command: %s", err))
+		return exitCode
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	repos := []RepoListEntry{}
+	position := 0
+	for _, entry := range config.RepositoryEntries() {
+		repo := RepoListEntry{
+			Repository: entry.Value,
+			Enabled:    !entry.Disabled,
+			File:       entry.File,
+			Overrides:  config.Overrides[entry.File]}
+		if repo.Enabled {
+			position++
+			repo.Position = position
+		}
+		repos = append(repos, repo)
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(repos); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	if len(repos) == 0 {
+		c.UI.Warn("No repositories configured")
+		return 0
+	}
+	rows := [][]string{}
+	for _, repo := range repos {
+		order := "-"
+		status := "disabled"
+		if repo.Enabled {
+			order = fmt.Sprintf("%d", repo.Position)
+			status = "enabled"
+		}
+		source := repo.File
+		if repo.Overrides != "" {
+			source = source + " (overrides " + repo.Overrides + ")"
+		}
+		rows = append(rows, []string{order, status, repo.Repository, source})
+	}
+	c.outputTable([]string{"#", "STATUS", "REPOSITORY", "FILE"}, rows)
+	return 0
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"fmt"
+	"time"
+)
+
+type RepoMirrorListCommand struct {
+	RepoCommand
+	Prober MirrorProber
+}
+
+func (c *RepoMirrorListCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup repository command: %s", err))
+		return exitCode
+	}
+	mirrors, err := c.mirrors(cOpts)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load mirror list: %s", err))
+		return exitCode
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	current, _, _ := splitMirrorURL(c.mainURL(config))
+	ranked := cOpts.Get("rank") != nil
+	if ranked {
+		mirrors = RankMirrors(mirrors, mirrorProber(c.Prober))
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(mirrors); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	header := []string{"", "MIRROR", "REGION"}
+	if ranked {
+		header = append(header, "LATENCY")
+	}
+	rows := [][]string{}
+	for _, mirror := range mirrors {
+		mark := ""
+		if mirror.URL == current {
+			mark = "*"
+		}
+		row := []string{mark, mirror.URL, mirror.Region}
+		if ranked {
+			if mirror.Error != "" {
+				row = append(row, "unreachable")
+			} else {
+				row = append(row, mirror.Latency.Round(time.Microsecond).String())
+			}
+		}
+		rows = append(rows, row)
+	}
+	c.outputTable(header, rows)
+	return 0
+}
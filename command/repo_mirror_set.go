@@ -0,0 +1,101 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RepoMirrorSetCommand struct {
+	RepoCommand
+	Prober MirrorProber
+}
+
+func (c *RepoMirrorSetCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args, false)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup repository command: %s", err))
+		return exitCode
+	}
+	mirror := ""
+	if cOpts.Get("fastest") != nil {
+		if len(cOpts.Args) != 0 {
+			c.UI.Error("Mirror URL cannot be combined with `--fastest`!")
+			return exitCode
+		}
+		mirrors, err := c.mirrors(cOpts)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to load mirror list: %s", err))
+			return exitCode
+		}
+		ranked := RankMirrors(mirrors, mirrorProber(c.Prober))
+		if ranked[0].Error != "" {
+			c.UI.Error("No mirror is reachable!")
+			return exitCode
+		}
+		mirror = ranked[0].URL
+		c.UI.Info(fmt.Sprintf(
+			"Fastest mirror: %s (%s)", mirror, ranked[0].Latency))
+	} else {
+		if len(cOpts.Args) != 1 {
+			c.UI.Error("Single mirror URL required!")
+			return exitCode
+		}
+		mirror = strings.TrimRight(cOpts.Args[0], "/")
+		if !strings.Contains(mirror, "://") {
+			c.UI.Error(fmt.Sprintf(
+				"Invalid mirror URL `%s`", mirror))
+			return exitCode
+		}
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	entries := []XbpsConfEntry{}
+	for _, entry := range config.RepositoryEntries() {
+		if base, _, ok := splitMirrorURL(entry.Value); ok && base != mirror {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		c.UI.Info(fmt.Sprintf(
+			"Repositories already use mirror: %s", mirror))
+		return 0
+	}
+	_, err = c.editEntries(config, entries, func(line string) []string {
+		prefix := ""
+		if disabledRepoLine(line) != "" {
+			prefix = "#"
+			line = disabledRepoLine(line)
+		}
+		// Keep trailing comments but match the path without them
+		comment := ""
+		if idx := strings.Index(line, "#"); idx != -1 {
+			comment = " " + line[idx:]
+			line = line[:idx]
+		}
+		parts := strings.SplitN(line, "=", 2)
+		_, path, _ := splitMirrorURL(strings.TrimSpace(parts[1]))
+		return []string{prefix + "repository=" + mirror + path + comment}
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write repository configuration: %s", err))
+		return exitCode
+	}
+	for _, entry := range entries {
+		_, path, _ := splitMirrorURL(entry.Value)
+		c.UI.Info(fmt.Sprintf(
+			"Updated repository: %s", mirror+path))
+	}
+	return 0
+}
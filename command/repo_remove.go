@@ -0,0 +1,45 @@
+package command
+
+import (
+	"fmt"
+)
+
+type RepoRemoveCommand struct {
+	RepoCommand
+}
+
+func (c *RepoRemoveCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	_, err := c.Init(args, true)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup repository command: %s", err))
+		return exitCode
+	}
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load xbps configuration: %s", err))
+		return exitCode
+	}
+	entries, err := c.findEntries(config, c.Repo)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	_, err = c.editEntries(config, entries, func(line string) []string {
+		return []string{}
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write repository configuration: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf(
+		"Removed repository: %s", entries[0].Value))
+	return 0
+}
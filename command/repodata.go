@@ -0,0 +1,145 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Package index of a single repository, read from its cached repodata
+type RepoIndex struct {
+	URL      string
+	Path     string
+	Packages map[string]*Package
+}
+
+// Directory holding the repodata for the given repository. Remote
+// repositories are cached in the xbps database directory using the
+// same escaping as xbps, local repositories hold their own repodata.
+func repoDataDir(rootDir string, url string) string {
+	url = strings.TrimRight(url, "/")
+	if strings.Contains(url, "://") {
+		escaped := strings.NewReplacer(".", "_", ":", "_", "/", "_").Replace(url)
+		return filepath.Join(rootDir, XBPS_DB_PATH, escaped)
+	}
+	return filepath.Join(rootDir, url)
+}
+
+// Locates the repodata archives for the given repository
+func repoDataFiles(rootDir string, url string, arch string) ([]string, error) {
+	pattern := "*-repodata"
+	if arch != "" {
+		pattern = arch + "-repodata"
+	}
+	return filepath.Glob(filepath.Join(repoDataDir(rootDir, url), pattern))
+}
+
+// Loads the indexes of all configured repositories which have
+// repodata available. Repositories are returned in configured order.
+func (c *PkgCommand) RepoIndexes() ([]*RepoIndex, error) {
+	config, err := LoadXbpsConfig(c.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	indexes := []*RepoIndex{}
+	arch := config.Architecture()
+	for _, repo := range config.Repositories() {
+		paths, err := repoDataFiles(c.RootDir, repo.Value, arch)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			c.debug(fmt.Sprintf(
+				"No repodata found for repository `%s`", repo.Value))
+			continue
+		}
+		for _, path := range paths {
+			index, err := c.loadRepoIndex(repo.Value, path)
+			if err != nil {
+				return nil, err
+			}
+			indexes = append(indexes, index)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, errors.New(
+			"No repository index files found (run `xbps-install -S` to synchronize)")
+	}
+	return indexes, nil
+}
+
+func (c *PkgCommand) loadRepoIndex(url string, path string) (*RepoIndex, error) {
+	c.debug(fmt.Sprintf("Reading repository index `%s`", path))
+	members, err := c.readArchive(path, func(name string) bool {
+		return strings.TrimPrefix(name, "./") == "index.plist"
+	})
+	if err != nil {
+		return nil, err
+	}
+	index := &RepoIndex{
+		URL:      url,
+		Path:     path,
+		Packages: map[string]*Package{}}
+	for _, content := range members {
+		value, err := ParsePlist(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		dict, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: index is not a dictionary", path)
+		}
+		for name, value := range dict {
+			if pkgDict, ok := value.(map[string]interface{}); ok {
+				pkg := newPackage(name, pkgDict)
+				pkg.Repository = url
+				index.Packages[name] = pkg
+			}
+		}
+	}
+	return index, nil
+}
+
+// Loads the file index of a repository from the `index-files.plist`
+// member of its repodata, keyed by package name. Repositories without
+// a file index return an empty map.
+func (c *PkgCommand) repoFileIndex(index *RepoIndex) (map[string][]string, error) {
+	files := map[string][]string{}
+	members, err := c.readArchive(index.Path, func(name string) bool {
+		return strings.TrimPrefix(name, "./") == "index-files.plist"
+	})
+	if err != nil {
+		return files, err
+	}
+	for _, content := range members {
+		value, err := ParsePlist(bytes.NewReader(content))
+		if err != nil {
+			return files, fmt.Errorf("%s: %s", index.Path, err)
+		}
+		dict, ok := value.(map[string]interface{})
+		if !ok {
+			return files, fmt.Errorf("%s: file index is not a dictionary", index.Path)
+		}
+		for key, value := range dict {
+			// Entries may be keyed by pkgver instead of name
+			name := key
+			if _, ok := index.Packages[key]; !ok {
+				if pkgName, _, err := splitPkgVer(key); err == nil {
+					name = pkgName
+				}
+			}
+			list, _ := value.([]interface{})
+			for _, item := range list {
+				switch entry := item.(type) {
+				case string:
+					files[name] = append(files[name], entry)
+				case map[string]interface{}:
+					files[name] = append(files[name], plistString(entry, "file"))
+				}
+			}
+		}
+	}
+	return files, nil
+}
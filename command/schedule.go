@@ -0,0 +1,512 @@
+package command
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/cli"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const CRON_D_PATH = "/etc/cron.d"
+const CRONTAB_PATH = "/etc/crontab"
+const CRON_SPOOL_PATH = "/var/spool/cron"
+const CROND_PATH = "/usr/bin/crond"
+const SNOOZE_PATH = "/usr/bin/snooze"
+const DCRON_SERVICE = "dcron"
+const RUNSVDIR_DEFAULT_PATH = "/etc/runit/runsvdir/default"
+const SCHEDULE_SERVICE_PREFIX = "schedule-"
+const SCHEDULE_MARKER = "# Managed by void schedule"
+const SCHEDULE_USER_TAG = "# user: "
+const SCHEDULE_COMMAND_TAG = "# command: "
+const SNOOZE_TIMEFILE = "timefile"
+
+var scheduleNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Cron field names in order
+var cronFields = []string{"minute", "hour", "day", "month", "weekday"}
+
+// snooze options matching the cron fields
+var snoozeOptions = []string{"-M", "-H", "-d", "-m", "-w"}
+
+var cronAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *"}
+
+// Schedule command stub
+type ScheduleCommand struct {
+	CoreCommand
+	RootDir string
+}
+
+// Scheduled job from a crontab or a snooze service
+type ScheduledJob struct {
+	Name     string `json:"name"`
+	Backend  string `json:"backend"`
+	Schedule string `json:"schedule"`
+	User     string `json:"user,omitempty"`
+	Command  string `json:"command"`
+	Source   string `json:"source"`
+	Managed  bool   `json:"managed"`
+	Enabled  bool   `json:"enabled"`
+}
+
+func (c *ScheduleCommand) Commands(appName string, ui cli.Ui, debug bool) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"schedule add": func() (cli.Command, error) {
+			return &ScheduleAddCommand{
+				ScheduleCommand: ScheduleCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void schedule add NAME --at SCHEDULE --command CMD",
+						SynopsisText: "Add a scheduled job",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "at",
+								Description: "Cron schedule (`MIN HOUR DAY MONTH WEEKDAY` or @daily, @hourly, ...)"},
+							CoreFlag{
+								Name:        "command",
+								Description: "Command to run"},
+							CoreFlag{
+								Name:        "backend",
+								Description: "Scheduler to use: cron or snooze (detected by default)"},
+							CoreFlag{
+								Name:        "user",
+								Description: "User to run the job as",
+								Default:     "root"},
+							CoreFlag{
+								Name:        "no-enable",
+								Boolean:     true,
+								Description: "Do not enable the snooze service"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"schedule list": func() (cli.Command, error) {
+			return &ScheduleListCommand{
+				ScheduleCommand: ScheduleCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void schedule list",
+						SynopsisText: "List scheduled jobs",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"schedule remove": func() (cli.Command, error) {
+			return &ScheduleRemoveCommand{
+				ScheduleCommand: ScheduleCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void schedule remove NAME",
+						SynopsisText: "Remove a scheduled job",
+						Flags:        c.flags(),
+						UI:           ui,
+						AppName:      appName,
+					},
+				},
+			}, nil
+		},
+	}
+}
+
+// Flags shared by all schedule commands
+func (c *ScheduleCommand) flags(extra ...CoreFlag) []CoreFlag {
+	return append([]CoreFlag{rootDirFlag()}, extra...)
+}
+
+func (c *ScheduleCommand) Init(args []string) (ParsedCli, error) {
+	fmtOpts, err := c.Parse(args)
+	if err != nil {
+		return fmtOpts, err
+	}
+	c.RootDir = fmtOpts.RootDir()
+	return fmtOpts, nil
+}
+
+// Collects jobs from /etc/crontab, /etc/cron.d, user crontabs and
+// services wrapping snooze
+func (c *ScheduleCommand) Jobs() ([]*ScheduledJob, error) {
+	jobs := []*ScheduledJob{}
+	files, _ := filepath.Glob(filepath.Join(c.RootDir, CRON_D_PATH, "*"))
+	files = append([]string{filepath.Join(c.RootDir, CRONTAB_PATH)}, files...)
+	// dcron runs system crontabs as root without a user column
+	systemUser := ""
+	if c.cronDaemon() == DCRON_SERVICE {
+		systemUser = "root"
+	}
+	for _, path := range files {
+		fileJobs, err := c.crontabJobs(path, systemUser)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, fileJobs...)
+	}
+	// cronie keeps user crontabs in the spool directory, dcron below
+	// a crontabs subdirectory
+	for _, pattern := range []string{"*", "crontabs/*"} {
+		spool, _ := filepath.Glob(filepath.Join(c.RootDir, CRON_SPOOL_PATH, pattern))
+		for _, path := range spool {
+			if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			fileJobs, err := c.crontabJobs(path, filepath.Base(path))
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, fileJobs...)
+		}
+	}
+	snoozeJobs, err := c.snoozeJobs()
+	if err != nil {
+		return nil, err
+	}
+	jobs = append(jobs, snoozeJobs...)
+	return jobs, nil
+}
+
+func (c *ScheduleCommand) Job(name string) (*ScheduledJob, error) {
+	jobs, err := c.Jobs()
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.Managed && job.Name == name {
+			return job, nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// Parses a crontab. Without a user the crontab has a user column
+// (cronie system crontabs), otherwise jobs run as the given user unless
+// wrapped with chpst. Jobs in files created by this command are named
+// after the file, others after the file and line.
+func (c *ScheduleCommand) crontabJobs(path string, user string) ([]*ScheduledJob, error) {
+	jobs := []*ScheduledJob{}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return jobs, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	source := c.rootPath(path)
+	managed := false
+	lineNo := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == SCHEDULE_MARKER {
+			managed = true
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		// Environment assignments
+		if strings.Contains(fields[0], "=") {
+			continue
+		}
+		scheduleLen := 5
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleLen = 1
+		}
+		columns := scheduleLen + 1
+		if user == "" {
+			columns++
+		}
+		if len(fields) < columns {
+			continue
+		}
+		job := &ScheduledJob{
+			Name:     fmt.Sprintf("%s:%d", filepath.Base(path), lineNo),
+			Backend:  "cron",
+			Schedule: strings.Join(fields[:scheduleLen], " "),
+			User:     user,
+			Source:   source,
+			Managed:  managed,
+			Enabled:  true}
+		if managed {
+			job.Name = filepath.Base(path)
+		}
+		if user == "" {
+			job.User = fields[scheduleLen]
+		}
+		job.Command = strings.Replace(skipFields(line, columns-1), `\%`, "%", -1)
+		if chpst := chpstUser(job.Command); chpst != "" {
+			job.User = chpst
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, scanner.Err()
+}
+
+// Finds runit services whose run script execs snooze
+func (c *ScheduleCommand) snoozeJobs() ([]*ScheduledJob, error) {
+	jobs := []*ScheduledJob{}
+	scripts, err := filepath.Glob(filepath.Join(c.RootDir, SERVICES_PATH, "*", "run"))
+	if err != nil {
+		return nil, err
+	}
+	for _, script := range scripts {
+		file, err := os.Open(script)
+		if err != nil {
+			continue
+		}
+		service := filepath.Base(filepath.Dir(script))
+		job := &ScheduledJob{Backend: "snooze", Name: service, Source: c.rootPath(script)}
+		// Managed services record the user and command as the exec
+		// line wraps them for touching the timefile
+		user, command := "", ""
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			switch {
+			case line == SCHEDULE_MARKER:
+				job.Managed = true
+			case strings.HasPrefix(line, SCHEDULE_USER_TAG):
+				user = strings.TrimPrefix(line, SCHEDULE_USER_TAG)
+			case strings.HasPrefix(line, SCHEDULE_COMMAND_TAG):
+				command = strings.TrimPrefix(line, SCHEDULE_COMMAND_TAG)
+			}
+			fields := strings.Fields(line)
+			if len(fields) < 2 || fields[0] != "exec" || filepath.Base(fields[1]) != "snooze" {
+				continue
+			}
+			job.Schedule, job.Command = snoozeSchedule(fields[2:])
+		}
+		file.Close()
+		if job.Schedule == "" {
+			continue
+		}
+		job.User = "root"
+		if chpst := chpstUser(job.Command); chpst != "" {
+			job.User = chpst
+		}
+		if job.Managed {
+			job.Name = strings.TrimPrefix(service, SCHEDULE_SERVICE_PREFIX)
+			if user != "" {
+				job.User = user
+			}
+			if command != "" {
+				job.Command = command
+			}
+		}
+		job.Enabled = c.snoozeEnabled(service)
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Converts snooze arguments back to a cron schedule and the command
+func snoozeSchedule(args []string) (string, string) {
+	fields := []string{"0", "0", "*", "*", "*"}
+	idx := 0
+	for ; idx < len(args) && strings.HasPrefix(strings.Trim(args[idx], `'"`), "-"); idx++ {
+		option := strings.Trim(args[idx], `'"`)
+		if len(option) == 2 && idx+1 < len(args) {
+			idx++
+			option = option + strings.Trim(args[idx], `'"`)
+		}
+		for pos, snoozeOption := range snoozeOptions {
+			if strings.HasPrefix(option, snoozeOption) {
+				value := strings.Trim(option[2:], `'"`)
+				if strings.HasPrefix(value, "/") {
+					value = "*" + value
+				}
+				fields[pos] = strings.Replace(value, `\*`, "*", -1)
+			}
+		}
+	}
+	return strings.Join(fields, " "), strings.Join(args[idx:], " ")
+}
+
+// Converts a cron schedule to snooze options. snooze defaults hour and
+// minute to zero, so wildcards are passed explicitly and escaped for
+// the run script. cron runs when either the day or the weekday matches
+// if both are restricted while snooze requires both to match, so such
+// schedules are rejected.
+func cronToSnooze(schedule string) ([]string, error) {
+	fields, err := parseCronSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	if fields[2] != "*" && fields[4] != "*" {
+		return nil, errors.New(
+			"Schedules restricting both day and weekday are not supported by snooze")
+	}
+	if fields[4], err = snoozeWeekday(fields[4]); err != nil {
+		return nil, err
+	}
+	options := []string{}
+	for idx, field := range fields {
+		if field == "*" && idx > 1 {
+			continue
+		}
+		value := field
+		if strings.HasPrefix(value, "*/") {
+			value = value[1:]
+		}
+		options = append(options, snoozeOptions[idx]+strings.Replace(value, "*", `\*`, -1))
+	}
+	return options, nil
+}
+
+// Translates cron weekday 7 (Sunday) to 0 as snooze only accepts 0-6
+func snoozeWeekday(field string) (string, error) {
+	parts := []string{}
+	for _, part := range strings.Split(field, ",") {
+		switch {
+		case part == "7":
+			part = "0"
+		case strings.HasSuffix(part, "-7"):
+			switch start := strings.TrimSuffix(part, "-7"); start {
+			case "0":
+				part = "0-6"
+			case "7":
+				part = "0"
+			case "6":
+				parts = append(parts, "0")
+				part = "6"
+			default:
+				parts = append(parts, "0")
+				part = start + "-6"
+			}
+		case strings.Contains(part, "7/"):
+			return "", fmt.Errorf("Weekday step `%s` ending on 7 is not supported by snooze", part)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// Validates a cron schedule returning its five fields
+func parseCronSchedule(schedule string) ([]string, error) {
+	if alias, ok := cronAliases[strings.TrimSpace(schedule)]; ok {
+		schedule = alias
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("Invalid schedule `%s` (expected 5 fields or an @ alias)", schedule)
+	}
+	valid := regexp.MustCompile(`^(\*|[0-9]+(-[0-9]+)?)(/[0-9]+)?(,[0-9]+(-[0-9]+)?(/[0-9]+)?)*$`)
+	for idx, field := range fields {
+		if !valid.MatchString(field) {
+			return nil, fmt.Errorf("Invalid %s field `%s`", cronFields[idx], field)
+		}
+	}
+	return fields, nil
+}
+
+// Picks snooze when it is installed without a cron daemon
+func (c *ScheduleCommand) detectBackend() string {
+	_, cronErr := os.Stat(filepath.Join(c.RootDir, CROND_PATH))
+	_, snoozeErr := os.Stat(filepath.Join(c.RootDir, SNOOZE_PATH))
+	if cronErr != nil && snoozeErr == nil {
+		return "snooze"
+	}
+	return "cron"
+}
+
+// Installed cron daemon. cronie and dcron both provide crond so the
+// daemon is identified by its service directory.
+func (c *ScheduleCommand) cronDaemon() string {
+	if _, err := os.Stat(filepath.Join(c.RootDir, SERVICES_PATH, DCRON_SERVICE)); err == nil {
+		return DCRON_SERVICE
+	}
+	return "cronie"
+}
+
+// Wraps a command to run as the given user
+func chpstCommand(user string, command string) string {
+	return fmt.Sprintf("chpst -u %s /bin/sh -c %s", shellQuote(user), shellQuote(command))
+}
+
+// User of a command wrapped by chpstCommand
+func chpstUser(command string) string {
+	words := strings.Fields(command)
+	if len(words) > 2 && words[0] == "chpst" && words[1] == "-u" {
+		return strings.Trim(words[2], `'"`)
+	}
+	return ""
+}
+
+func (c *ScheduleCommand) validateName(name string) error {
+	if !scheduleNamePattern.MatchString(name) {
+		return errors.New(fmt.Sprintf(
+			"Invalid job name `%s` (only letters, digits, `-` and `_` allowed)", name))
+	}
+	return nil
+}
+
+// Service tooling bound to the live system
+func (c *ScheduleCommand) service(name string) *ServiceCommand {
+	return &ServiceCommand{CoreCommand: c.CoreCommand, ServiceName: name}
+}
+
+// Enabled state of a service. Alternate roots are checked in the
+// default runsvdir as /var/service only exists on a running system.
+func (c *ScheduleCommand) snoozeEnabled(service string) bool {
+	if c.RootDir == "/" {
+		return c.service(service).ServiceIsEnabled()
+	}
+	_, err := os.Lstat(filepath.Join(c.RootDir, RUNSVDIR_DEFAULT_PATH, service))
+	return err == nil
+}
+
+func (c *ScheduleCommand) rootPath(path string) string {
+	rel, err := filepath.Rel(c.RootDir, path)
+	if err != nil {
+		return path
+	}
+	return "/" + rel
+}
+
+func sortJobs(jobs []*ScheduledJob) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].Managed != jobs[j].Managed {
+			return jobs[i].Managed
+		}
+		return jobs[i].Name < jobs[j].Name
+	})
+}
+
+// Drops the leading whitespace separated fields from a line, keeping
+// the spacing of the remainder intact
+func skipFields(line string, count int) string {
+	for i := 0; i < count; i++ {
+		line = strings.TrimLeft(line, " \t")
+		if idx := strings.IndexAny(line, " \t"); idx != -1 {
+			line = line[idx:]
+		} else {
+			line = ""
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// Quotes a string for use as a single shell word
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
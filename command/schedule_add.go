@@ -0,0 +1,160 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type ScheduleAddCommand struct {
+	ScheduleCommand
+}
+
+func (c *ScheduleAddCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup schedule command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single job name required!")
+		return exitCode
+	}
+	name := cOpts.Args[0]
+	if err := c.validateName(name); err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	schedule, command := "", ""
+	if flag := cOpts.Get("at"); flag != nil {
+		schedule = flag.Value
+	}
+	if flag := cOpts.Get("command"); flag != nil {
+		command = strings.TrimSpace(flag.Value)
+	}
+	if schedule == "" || command == "" {
+		c.UI.Error("Both `--at` and `--command` are required!")
+		return exitCode
+	}
+	if _, err := parseCronSchedule(schedule); err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	if _, err := c.Job(name); err == nil {
+		c.UI.Error(fmt.Sprintf(
+			"Scheduled job `%s` already exists!", name))
+		return exitCode
+	}
+	backend := c.detectBackend()
+	if flag := cOpts.Get("backend"); flag != nil && flag.Value != "" {
+		backend = flag.Value
+	}
+	user := "root"
+	if flag := cOpts.Get("user"); flag != nil && flag.Value != "" {
+		user = flag.Value
+	}
+	switch backend {
+	case "cron":
+		err = c.addCronJob(name, schedule, user, command)
+	case "snooze":
+		err = c.addSnoozeJob(name, schedule, user, command, cOpts.Get("no-enable") == nil)
+	default:
+		c.UI.Error(fmt.Sprintf(
+			"Unknown backend `%s` (expected cron or snooze)", backend))
+		return exitCode
+	}
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to add scheduled job: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf("Added %s job: %s", backend, name))
+	return 0
+}
+
+// Writes the job to /etc/cron.d. cron reads `%` as a newline so it is
+// escaped. cronie expects a user column, dcron runs these jobs as root
+// so other users are switched to with chpst.
+func (c *ScheduleAddCommand) addCronJob(name string, schedule string, user string, command string) error {
+	if strings.ContainsAny(command, "\r\n") {
+		return errors.New("Commands for cron must be a single line")
+	}
+	dir := filepath.Join(c.RootDir, CRON_D_PATH)
+	path := filepath.Join(dir, name)
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("Crontab `%s` already exists", filepath.Join(CRON_D_PATH, name))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	line := strings.TrimSpace(schedule)
+	if c.cronDaemon() == DCRON_SERVICE {
+		if user != "root" {
+			command = chpstCommand(user, command)
+		}
+	} else {
+		line = line + " " + user
+	}
+	command = strings.Replace(command, "%", `\%`, -1)
+	content := fmt.Sprintf("%s\n%s %s\n", SCHEDULE_MARKER, line, command)
+	return writeFileAtomic(path, []byte(content), 0644)
+}
+
+// Generates a runit service running the command through snooze. snooze
+// waits for the next matching time, runs the command and exits, after
+// which runit restarts it to wait for the next run. The timefile is
+// touched after each run, whether the command failed or not, so snooze
+// waits for the following match instead of firing again within its
+// slack window.
+func (c *ScheduleAddCommand) addSnoozeJob(name string, schedule string, user string, command string, enable bool) error {
+	if strings.ContainsAny(command, "\r\n") {
+		return errors.New("Commands for snooze must be a single line")
+	}
+	options, err := cronToSnooze(schedule)
+	if err != nil {
+		return err
+	}
+	service := SCHEDULE_SERVICE_PREFIX + name
+	timefile := filepath.Join(SERVICES_PATH, service, SNOOZE_TIMEFILE)
+	run := "/bin/sh -c " + shellQuote(command)
+	if user != "root" {
+		run = chpstCommand(user, command)
+	}
+	run = "/bin/sh -c " + shellQuote(run+"; touch "+timefile)
+	dir := filepath.Join(c.RootDir, SERVICES_PATH, service)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("Service `%s` already exists", service)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	content := fmt.Sprintf("#!/bin/sh\n%s\n%s%s\n%s%s\nexec %s -t %s %s %s\n",
+		SCHEDULE_MARKER, SCHEDULE_USER_TAG, user, SCHEDULE_COMMAND_TAG, command,
+		SNOOZE_PATH, timefile, strings.Join(options, " "), run)
+	if err := writeFileAtomic(filepath.Join(dir, "run"), []byte(content), 0755); err != nil {
+		return err
+	}
+	// Start counting from now rather than the epoch
+	if err := writeFileAtomic(filepath.Join(dir, SNOOZE_TIMEFILE), []byte{}, 0644); err != nil {
+		return err
+	}
+	if !enable {
+		return nil
+	}
+	if c.RootDir == "/" {
+		return c.service(service).EnableService()
+	}
+	runsvdir := filepath.Join(c.RootDir, RUNSVDIR_DEFAULT_PATH)
+	if err := os.MkdirAll(runsvdir, 0755); err != nil {
+		return err
+	}
+	return os.Symlink(filepath.Join(SERVICES_PATH, service), filepath.Join(runsvdir, service))
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"fmt"
+)
+
+type ScheduleListCommand struct {
+	ScheduleCommand
+}
+
+func (c *ScheduleListCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup schedule command: %s", err))
+		return exitCode
+	}
+	jobs, err := c.Jobs()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to list scheduled jobs: %s", err))
+		return exitCode
+	}
+	sortJobs(jobs)
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(jobs); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	if len(jobs) == 0 {
+		c.UI.Info("No scheduled jobs found")
+		return 0
+	}
+	rows := [][]string{}
+	for _, job := range jobs {
+		status := "enabled"
+		if !job.Enabled {
+			status = "disabled"
+		}
+		if !job.Managed {
+			status = status + " (unmanaged)"
+		}
+		rows = append(rows, []string{job.Name, job.Backend, job.Schedule, job.User, status, job.Command})
+	}
+	c.outputTable([]string{"NAME", "BACKEND", "SCHEDULE", "USER", "STATUS", "COMMAND"}, rows)
+	return 0
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type ScheduleRemoveCommand struct {
+	ScheduleCommand
+}
+
+func (c *ScheduleRemoveCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup schedule command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single job name required!")
+		return exitCode
+	}
+	name := cOpts.Args[0]
+	job, err := c.Job(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.UI.Error(fmt.Sprintf(
+				"Scheduled job `%s` does not exist or is not managed by void!", name))
+		} else {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to load scheduled jobs: %s", err))
+		}
+		return exitCode
+	}
+	switch job.Backend {
+	case "cron":
+		err = os.Remove(filepath.Join(c.RootDir, job.Source))
+	case "snooze":
+		err = c.removeSnoozeJob(job)
+	}
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to remove scheduled job: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf("Removed %s job: %s", job.Backend, name))
+	return 0
+}
+
+// Stops and disables the snooze service before removing it
+func (c *ScheduleRemoveCommand) removeSnoozeJob(job *ScheduledJob) error {
+	service := SCHEDULE_SERVICE_PREFIX + job.Name
+	if job.Enabled {
+		if c.RootDir == "/" {
+			svc := c.service(service)
+			if svc.ServiceIsRunning() && !svc.StopService() {
+				c.UI.Warn(fmt.Sprintf("Failed to stop service `%s`", service))
+			}
+			if err := svc.DisableService(); err != nil {
+				return err
+			}
+		} else if err := os.Remove(filepath.Join(c.RootDir, RUNSVDIR_DEFAULT_PATH, service)); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(filepath.Join(c.RootDir, SERVICES_PATH, service))
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	cases := []struct {
+		schedule string
+		fields   string
+		valid    bool
+	}{
+		{"*/15 8-17 * * 1-5", "*/15 8-17 * * 1-5", true},
+		{"0 0 1,15 * *", "0 0 1,15 * *", true},
+		{"@daily", "0 0 * * *", true},
+		{"@weekly", "0 0 * * 0", true},
+		{"0 0 * *", "", false},
+		{"0 0 * * * *", "", false},
+		{"x 0 * * *", "", false},
+		{"0 0 * * mon", "", false},
+		{"@sometimes", "", false},
+	}
+	for _, tc := range cases {
+		fields, err := parseCronSchedule(tc.schedule)
+		if (err == nil) != tc.valid {
+			t.Errorf("parseCronSchedule(%q) error = %v, expected valid %t", tc.schedule, err, tc.valid)
+			continue
+		}
+		if tc.valid && strings.Join(fields, " ") != tc.fields {
+			t.Errorf("parseCronSchedule(%q) = %v, expected %s", tc.schedule, fields, tc.fields)
+		}
+	}
+}
+
+func TestCronToSnooze(t *testing.T) {
+	cases := []struct {
+		schedule string
+		options  string
+		valid    bool
+	}{
+		{"*/15 8-17 * * 1-5", `-M/15 -H8-17 -w1-5`, true},
+		{"* * * * *", `-M\* -H\*`, true},
+		{"30 2 1 * *", `-M30 -H2 -d1`, true},
+		{"0 0 * 6 *", `-M0 -H0 -m6`, true},
+		{"0 0 * * 7", `-M0 -H0 -w0`, true},
+		{"0 0 * * 5-7", `-M0 -H0 -w0,5-6`, true},
+		{"0 0 * * 0-7", `-M0 -H0 -w0-6`, true},
+		{"0 0 * * 1,7", `-M0 -H0 -w1,0`, true},
+		{"0 0 * * 1-7/2", "", false},
+		{"0 0 1 * 1", "", false},
+	}
+	for _, tc := range cases {
+		options, err := cronToSnooze(tc.schedule)
+		if (err == nil) != tc.valid {
+			t.Errorf("cronToSnooze(%q) error = %v, expected valid %t", tc.schedule, err, tc.valid)
+			continue
+		}
+		if tc.valid && strings.Join(options, " ") != tc.options {
+			t.Errorf("cronToSnooze(%q) = %s, expected %s", tc.schedule, strings.Join(options, " "), tc.options)
+		}
+	}
+}
+
+func TestSnoozeScheduleRoundTrip(t *testing.T) {
+	schedules := []string{
+		"*/15 8-17 * * 1-5",
+		"* * * * *",
+		"30 2 1 * *",
+		"0 0 * 6 *",
+		"0 12 * * 0",
+	}
+	for _, schedule := range schedules {
+		options, err := cronToSnooze(schedule)
+		if err != nil {
+			t.Fatalf("cronToSnooze(%q) failed: %s", schedule, err)
+		}
+		args := append([]string{"-t", "/etc/sv/schedule-x/timefile"}, options...)
+		args = append(args, "/bin/sh", "-c", "'date'")
+		result, command := snoozeSchedule(args)
+		if result != schedule {
+			t.Errorf("snoozeSchedule(%v) = %q, expected %q", options, result, schedule)
+		}
+		if command != "/bin/sh -c 'date'" {
+			t.Errorf("snoozeSchedule(%v) command = %q", options, command)
+		}
+	}
+	// Options given as separate arguments by hand written services
+	if result, _ := snoozeSchedule([]string{"-H", "3", "-M", "30", "cmd"}); result != "30 3 * * *" {
+		t.Errorf("snoozeSchedule() of separate arguments = %q", result)
+	}
+}
+
+func TestCrontabJobs(t *testing.T) {
+	root, err := ioutil.TempDir("", "void-schedule")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	c := &ScheduleCommand{RootDir: root}
+	header := SCHEDULE_MARKER + "\nSHELL=/bin/sh\n# comment\n"
+	cases := []struct {
+		// Fixed user of dcron crontabs, empty for a cronie user column
+		user     string
+		lines    string
+		schedule []string
+		users    []string
+		commands []string
+	}{
+		{"",
+			"0 3 * * 1\tbob  tar -czf /tmp/backup-$(date +\\%F).tgz /home\n@hourly root date +\\%H\n",
+			[]string{"0 3 * * 1", "@hourly"},
+			[]string{"bob", "root"},
+			[]string{"tar -czf /tmp/backup-$(date +%F).tgz /home", "date +%H"}},
+		{"root",
+			"0 3 * * 1\ttar -czf /tmp/backup-$(date +\\%F).tgz /home\n@hourly chpst -u 'alice' /bin/sh -c 'date +\\%H'\n",
+			[]string{"0 3 * * 1", "@hourly"},
+			[]string{"root", "alice"},
+			[]string{"tar -czf /tmp/backup-$(date +%F).tgz /home", "chpst -u 'alice' /bin/sh -c 'date +%H'"}},
+	}
+	for _, tc := range cases {
+		path := filepath.Join(root, "backup")
+		if err := ioutil.WriteFile(path, []byte(header+tc.lines), 0644); err != nil {
+			t.Fatal(err)
+		}
+		jobs, err := c.crontabJobs(path, tc.user)
+		if err != nil {
+			t.Fatalf("crontabJobs() failed: %s", err)
+		}
+		if len(jobs) != len(tc.schedule) {
+			t.Fatalf("crontabJobs() returned %d jobs, expected %d", len(jobs), len(tc.schedule))
+		}
+		for idx, job := range jobs {
+			if !job.Managed || job.Name != "backup" || job.Source != "/backup" {
+				t.Errorf("Job %d = %+v, expected managed job `backup`", idx, job)
+			}
+			if job.Schedule != tc.schedule[idx] || job.User != tc.users[idx] || job.Command != tc.commands[idx] {
+				t.Errorf("Job %d (user %q) = %q %q %q, expected %q %q %q", idx, tc.user,
+					job.Schedule, job.User, job.Command, tc.schedule[idx], tc.users[idx], tc.commands[idx])
+			}
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 )
 
 const SV_PATH = "/usr/bin/sv"
@@ -139,6 +140,17 @@ func (c *ServiceCommand) StopService() bool {
 	return c.ExecuteCommand(cmd) == 0
 }
 
+// Stops the service waiting up to the given number of seconds
+func (c *ServiceCommand) StopServiceWait(timeout int) bool {
+	cmd := exec.Command(SV_PATH, "-w", strconv.Itoa(timeout), "stop", c.ServiceName)
+	return c.ExecuteCommand(cmd) == 0
+}
+
+func (c *ServiceCommand) RestartService() bool {
+	cmd := exec.Command(SV_PATH, "restart", c.ServiceName)
+	return c.ExecuteCommand(cmd) == 0
+}
+
 func (c *ServiceCommand) AllServices() ([]string, error) {
 	return c.directoryList(SERVICES_PATH)
 }
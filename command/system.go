@@ -0,0 +1,299 @@
+package command
+
+import (
+	"fmt"
+	"github.com/mitchellh/cli"
+)
+
+// System command stub
+type SystemCommand struct {
+	CoreCommand
+	RootDir string
+}
+
+func (c *SystemCommand) Commands(appName string, ui cli.Ui, debug bool) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"system conf get": func() (cli.Command, error) {
+			return &SystemConfGetCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system conf get KEY",
+						SynopsisText: "Display an rc.conf setting",
+						Flags:        c.flags(),
+						UI:           ui,
+						AppName:      appName,
+					},
+				},
+			}, nil
+		},
+		"system conf list": func() (cli.Command, error) {
+			return &SystemConfListCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system conf list",
+						SynopsisText: "List rc.conf settings",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "all",
+								Boolean:     true,
+								Description: "Include commented defaults"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"system conf set": func() (cli.Command, error) {
+			return &SystemConfSetCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system conf set KEY VALUE",
+						SynopsisText: "Set an rc.conf setting",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "force",
+								Boolean:     true,
+								Description: "Skip value validation"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"system conf unset": func() (cli.Command, error) {
+			return &SystemConfUnsetCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system conf unset KEY",
+						SynopsisText: "Remove an rc.conf setting",
+						Flags:        c.flags(),
+						UI:           ui,
+						AppName:      appName,
+					},
+				},
+			}, nil
+		},
+		"system halt": func() (cli.Command, error) {
+			return &SystemPowerCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system halt [--when TIME] [--message MSG]",
+						SynopsisText: "Halt the system",
+						Flags:        c.powerFlags(),
+						UI:           ui,
+						AppName:      appName,
+					},
+				},
+				Action: "halt",
+			}, nil
+		},
+		"system hostname set": func() (cli.Command, error) {
+			return &SystemHostnameSetCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system hostname set NAME",
+						SynopsisText: "Set the system hostname",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "apply",
+								Boolean:     true,
+								Description: "Also apply the hostname to the running system"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"system locale disable": func() (cli.Command, error) {
+			return &SystemLocaleDisableCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system locale disable LOCALE...",
+						SynopsisText: "Disable locales",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "no-generate",
+								Boolean:     true,
+								Description: "Do not regenerate locales"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"system locale enable": func() (cli.Command, error) {
+			return &SystemLocaleEnableCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system locale enable LOCALE...",
+						SynopsisText: "Enable locales",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "no-generate",
+								Boolean:     true,
+								Description: "Do not regenerate locales"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"system locale list": func() (cli.Command, error) {
+			return &SystemLocaleListCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system locale list",
+						SynopsisText: "List available locales",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "enabled",
+								Boolean:     true,
+								Description: "Display enabled locales only"},
+							CoreFlag{
+								Name:        "json",
+								Boolean:     true,
+								Description: "Output as JSON"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"system locale set": func() (cli.Command, error) {
+			return &SystemLocaleSetCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system locale set LOCALE",
+						SynopsisText: "Set the system locale",
+						Flags: c.flags(
+							CoreFlag{
+								Name:        "no-generate",
+								Boolean:     true,
+								Description: "Do not regenerate locales"}),
+						UI:      ui,
+						AppName: appName,
+					},
+				},
+			}, nil
+		},
+		"system poweroff": func() (cli.Command, error) {
+			return &SystemPowerCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system poweroff [--when TIME] [--message MSG]",
+						SynopsisText: "Power off the system",
+						Flags:        c.powerFlags(),
+						UI:           ui,
+						AppName:      appName,
+					},
+				},
+				Action: "poweroff",
+			}, nil
+		},
+		"system reboot": func() (cli.Command, error) {
+			return &SystemPowerCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system reboot [--when TIME] [--message MSG]",
+						SynopsisText: "Reboot the system",
+						Flags:        c.powerFlags(),
+						UI:           ui,
+						AppName:      appName,
+					},
+				},
+				Action: "reboot",
+			}, nil
+		},
+		"system timezone set": func() (cli.Command, error) {
+			return &SystemTimezoneSetCommand{
+				SystemCommand: SystemCommand{
+					CoreCommand: CoreCommand{
+						Debug:        debug,
+						HelpText:     "void system timezone set ZONE",
+						SynopsisText: "Set the system timezone",
+						Flags:        c.flags(),
+						UI:           ui,
+						AppName:      appName,
+					},
+				},
+			}, nil
+		},
+	}
+}
+
+// Flags shared by all system commands
+func (c *SystemCommand) flags(extra ...CoreFlag) []CoreFlag {
+	return append([]CoreFlag{
+		rootDirFlag(),
+		CoreFlag{
+			Name:        "root",
+			Boolean:     false,
+			Description: "Same as --rootdir"}}, extra...)
+}
+
+// Flags shared by the power commands. These always act on the running
+// system so the root directory flag is not included.
+func (c *SystemCommand) powerFlags() []CoreFlag {
+	return []CoreFlag{
+		CoreFlag{
+			Name:        "when",
+			Description: "Time to act at: now, +MINUTES or HH:MM (waits in the foreground, hangups are ignored)",
+			Default:     "now"},
+		CoreFlag{
+			Name:        "message",
+			Description: "Message broadcast to logged in users"},
+		CoreFlag{
+			Name:        "stop",
+			Description: "Services to stop first (NAME[:SECONDS],...)"},
+		CoreFlag{
+			Name:        "dry-run",
+			Boolean:     true,
+			Description: "Only show what would be done"}}
+}
+
+func (c *SystemCommand) Init(args []string) (ParsedCli, error) {
+	fmtOpts, err := c.Parse(args)
+	if err != nil {
+		return fmtOpts, err
+	}
+	c.RootDir = fmtOpts.RootDir()
+	return fmtOpts, nil
+}
+
+// Updates a setting in rc.conf only when it is actively set there,
+// since an active rc.conf value overrides the dedicated file
+func (c *SystemCommand) syncRcConf(key string, value string) int {
+	conf, err := LoadRcConf(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load rc.conf: %s", err))
+		return 1
+	}
+	if current, ok := conf.Get(key); !ok || current == value {
+		return 0
+	}
+	conf.Set(key, value)
+	if err := conf.Save(); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write rc.conf: %s", err))
+		return 1
+	}
+	c.UI.Warn(fmt.Sprintf("Updated %s in %s to match", key, RC_CONF_PATH))
+	return 0
+}
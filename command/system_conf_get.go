@@ -0,0 +1,36 @@
+package command
+
+import (
+	"fmt"
+)
+
+type SystemConfGetCommand struct {
+	SystemCommand
+}
+
+func (c *SystemConfGetCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single setting name required!")
+		return exitCode
+	}
+	conf, err := LoadRcConf(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load rc.conf: %s", err))
+		return exitCode
+	}
+	value, ok := conf.Get(cOpts.Args[0])
+	if !ok {
+		c.debug(fmt.Sprintf("Setting `%s` is not set", cOpts.Args[0]))
+		return exitCode
+	}
+	c.UI.Output(value)
+	return 0
+}
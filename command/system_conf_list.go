@@ -0,0 +1,50 @@
+package command
+
+import (
+	"fmt"
+)
+
+type SystemConfListCommand struct {
+	SystemCommand
+}
+
+func (c *SystemConfListCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	conf, err := LoadRcConf(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load rc.conf: %s", err))
+		return exitCode
+	}
+	all := cOpts.Get("all") != nil
+	entries := []RcConfEntry{}
+	for _, entry := range conf.Entries() {
+		if all || !entry.Commented {
+			entries = append(entries, entry)
+		}
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(entries); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	rows := [][]string{}
+	for _, entry := range entries {
+		status := "set"
+		if entry.Commented {
+			status = "default"
+		}
+		rows = append(rows, []string{entry.Key, entry.Value, status})
+	}
+	c.outputTable([]string{"KEY", "VALUE", "STATUS"}, rows)
+	return 0
+}
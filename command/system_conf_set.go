@@ -0,0 +1,59 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type SystemConfSetCommand struct {
+	SystemCommand
+}
+
+func (c *SystemConfSetCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	key, value := "", ""
+	switch {
+	case len(cOpts.Args) == 2:
+		key, value = cOpts.Args[0], cOpts.Args[1]
+	case len(cOpts.Args) == 1 && strings.Contains(cOpts.Args[0], "="):
+		parts := strings.SplitN(cOpts.Args[0], "=", 2)
+		key, value = parts[0], parts[1]
+	default:
+		c.UI.Error("Setting name and value required!")
+		return exitCode
+	}
+	if cOpts.Get("force") == nil {
+		if err := validateRcConfValue(c.RootDir, key, value); err != nil {
+			c.UI.Error(err.Error())
+			return exitCode
+		}
+	}
+	conf, err := LoadRcConf(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load rc.conf: %s", err))
+		return exitCode
+	}
+	if current, ok := conf.Get(key); ok && current == value {
+		c.UI.Info(fmt.Sprintf("Setting `%s` is already `%s`", key, value))
+		return 0
+	}
+	conf.Set(key, value)
+	if err := conf.Save(); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write rc.conf: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf("Set %s=%s", key, quoteShellValue(value)))
+	return 0
+}
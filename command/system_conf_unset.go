@@ -0,0 +1,46 @@
+package command
+
+import (
+	"fmt"
+)
+
+type SystemConfUnsetCommand struct {
+	SystemCommand
+}
+
+func (c *SystemConfUnsetCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single setting name required!")
+		return exitCode
+	}
+	key := cOpts.Args[0]
+	conf, err := LoadRcConf(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load rc.conf: %s", err))
+		return exitCode
+	}
+	if !conf.Unset(key) {
+		c.UI.Error(fmt.Sprintf(
+			"Setting `%s` is not set!", key))
+		return exitCode
+	}
+	if err := conf.Save(); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write rc.conf: %s", err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf("Unset %s", key))
+	return 0
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"syscall"
+)
+
+type SystemHostnameSetCommand struct {
+	SystemCommand
+}
+
+func (c *SystemHostnameSetCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single hostname required!")
+		return exitCode
+	}
+	hostname := cOpts.Args[0]
+	if err := validateHostname(hostname); err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	path := filepath.Join(c.RootDir, HOSTNAME_PATH)
+	if err := writeFileAtomic(path, []byte(hostname+"\n"), 0644); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write %s: %s", HOSTNAME_PATH, err))
+		return exitCode
+	}
+	if code := c.syncRcConf("HOSTNAME", hostname); code != 0 {
+		return code
+	}
+	if c.RootDir == "/" && cOpts.Get("apply") != nil {
+		if err := syscall.Sethostname([]byte(hostname)); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to apply hostname: %s", err))
+			return exitCode
+		}
+	}
+	c.UI.Info(fmt.Sprintf("System hostname set to: %s", hostname))
+	return 0
+}
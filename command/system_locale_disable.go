@@ -0,0 +1,24 @@
+package command
+
+import (
+	"fmt"
+)
+
+type SystemLocaleDisableCommand struct {
+	SystemCommand
+}
+
+func (c *SystemLocaleDisableCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	return c.toggleLocales(cOpts.Args, false, cOpts)
+}
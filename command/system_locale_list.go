@@ -0,0 +1,55 @@
+package command
+
+import (
+	"fmt"
+)
+
+type SystemLocaleListCommand struct {
+	SystemCommand
+}
+
+func (c *SystemLocaleListCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	locales, err := LoadLibcLocales(c.RootDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to load locales: %s", err))
+		return exitCode
+	}
+	current := c.currentLocale()
+	list := []Locale{}
+	for _, locale := range locales.Locales() {
+		if cOpts.Get("enabled") != nil && !locale.Enabled {
+			continue
+		}
+		list = append(list, locale)
+	}
+	if cOpts.Get("json") != nil {
+		if err := c.outputJSON(list); err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to generate JSON output: %s", err))
+			return exitCode
+		}
+		return 0
+	}
+	rows := [][]string{}
+	for _, locale := range list {
+		mark := ""
+		if locale.Name == current {
+			mark = "*"
+		}
+		status := "disabled"
+		if locale.Enabled {
+			status = "enabled"
+		}
+		rows = append(rows, []string{mark, locale.Name, locale.Charset, status})
+	}
+	c.outputTable([]string{"", "LOCALE", "CHARSET", "STATUS"}, rows)
+	return 0
+}
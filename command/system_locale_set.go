@@ -0,0 +1,73 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type SystemLocaleSetCommand struct {
+	SystemCommand
+}
+
+func (c *SystemLocaleSetCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single locale required!")
+		return exitCode
+	}
+	name := cOpts.Args[0]
+	// musl systems have no locale list, C.UTF-8 is always available
+	if name != "C" && name != "C.UTF-8" && name != "POSIX" {
+		locales, err := LoadLibcLocales(c.RootDir)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf(
+				"Failed to load locales: %s", err))
+			return exitCode
+		}
+		locale, ok := locales.Find(name)
+		if !ok {
+			c.UI.Error(fmt.Sprintf(
+				"Unknown locale `%s`", name))
+			return exitCode
+		}
+		if !locale.Enabled {
+			if code := c.toggleLocales([]string{name}, true, cOpts); code != 0 {
+				return code
+			}
+		}
+	}
+	path := filepath.Join(c.RootDir, LOCALE_CONF_PATH)
+	lines := []string{}
+	if content, err := ioutil.ReadFile(path); err == nil {
+		for _, line := range splitLines(string(content)) {
+			if !strings.HasPrefix(strings.TrimSpace(line), "LANG=") {
+				lines = append(lines, line)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to read %s: %s", LOCALE_CONF_PATH, err))
+		return exitCode
+	}
+	lines = append([]string{"LANG=" + name}, lines...)
+	if err := writeFileAtomic(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to write %s: %s", LOCALE_CONF_PATH, err))
+		return exitCode
+	}
+	c.UI.Info(fmt.Sprintf("System locale set to: %s", name))
+	return 0
+}
@@ -0,0 +1,212 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const RUNIT_RUN_PATH = "/run/runit"
+const INIT_COMM_PATH = "/proc/1/comm"
+const WALL_PATH = "/usr/bin/wall"
+const POWER_STOP_TIMEOUT = 7
+
+// Handles reboot, poweroff and halt. runit only distinguishes reboot
+// from poweroff at stage 3, halt is handled like poweroff as done by
+// the void-runit halt utility.
+type SystemPowerCommand struct {
+	SystemCommand
+	Action string
+}
+
+// Service to stop before entering stage 3
+type powerStop struct {
+	Name    string
+	Timeout int
+}
+
+func (c *SystemPowerCommand) Run(args []string) int {
+	exitCode := 1
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	dryRun := cOpts.Get("dry-run") != nil
+	if !dryRun && !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	if err := c.checkRunit(); err != nil {
+		if !dryRun {
+			c.UI.Error(fmt.Sprintf(
+				"Cannot %s: %s", c.Action, err))
+			return exitCode
+		}
+		c.UI.Warn(err.Error())
+	}
+	when := time.Now()
+	if flag := cOpts.Get("when"); flag != nil {
+		if when, err = parsePowerTime(flag.Value, time.Now()); err != nil {
+			c.UI.Error(err.Error())
+			return exitCode
+		}
+	}
+	stops := []powerStop{}
+	if flag := cOpts.Get("stop"); flag != nil {
+		if stops, err = parsePowerStops(flag.Value); err != nil {
+			c.UI.Error(err.Error())
+			return exitCode
+		}
+	}
+	message := fmt.Sprintf("The system is going down for %s", c.Action)
+	if flag := cOpts.Get("message"); flag != nil && flag.Value != "" {
+		message = message + ": " + flag.Value
+	}
+	if dryRun {
+		c.UI.Output(fmt.Sprintf("Action:  %s", c.Action))
+		c.UI.Output(fmt.Sprintf("When:    %s", when.Format(time.RFC1123)))
+		c.UI.Output(fmt.Sprintf("Message: %s", message))
+		for _, stop := range stops {
+			c.UI.Output(fmt.Sprintf("Stop:    %s (timeout %ds)", stop.Name, stop.Timeout))
+		}
+		return 0
+	}
+	if delay := time.Until(when); delay > 0 {
+		// Keep waiting when the controlling terminal goes away, only
+		// an interrupt cancels the scheduled action
+		signal.Ignore(syscall.SIGHUP)
+		c.broadcast(fmt.Sprintf("%s at %s!", message, when.Format("15:04")))
+		c.UI.Info(fmt.Sprintf("Scheduled %s at %s (interrupt to cancel)", c.Action, when.Format(time.RFC1123)))
+		if delay > time.Minute {
+			time.Sleep(delay - time.Minute)
+			c.broadcast(fmt.Sprintf("%s in one minute!", message))
+			delay = time.Minute
+		}
+		time.Sleep(delay)
+	}
+	c.broadcast(message + " NOW!")
+	svc := &ServiceCommand{CoreCommand: c.CoreCommand}
+	for _, stop := range stops {
+		svc.ServiceName = stop.Name
+		if !svc.ServiceIsEnabled() {
+			c.UI.Warn(fmt.Sprintf("Service `%s` is not enabled, skipping", stop.Name))
+			continue
+		}
+		c.UI.Output(fmt.Sprintf("Stopping service `%s`...", stop.Name))
+		if !svc.StopServiceWait(stop.Timeout) {
+			c.UI.Warn(fmt.Sprintf(
+				"Service `%s` did not stop within %ds", stop.Name, stop.Timeout))
+		}
+	}
+	if err := c.enterStage3(); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to %s: %s", c.Action, err))
+		return exitCode
+	}
+	return 0
+}
+
+// Checks that runit is running as PID 1, otherwise the control files
+// and signal would be silently ignored
+func (c *SystemPowerCommand) checkRunit() error {
+	content, err := ioutil.ReadFile(INIT_COMM_PATH)
+	if err != nil {
+		return fmt.Errorf("Failed to determine init process: %s", err)
+	}
+	if init := strings.TrimSpace(string(content)); init != "runit" {
+		return fmt.Errorf("PID 1 is `%s`, not runit", init)
+	}
+	return nil
+}
+
+// Prepares the runit control files and signals runit to enter stage 3.
+// runit checks `stopit` when receiving SIGCONT and stage 3 reboots if
+// `reboot` is executable, otherwise the system is powered off.
+func (c *SystemPowerCommand) enterStage3() error {
+	if err := os.MkdirAll(RUNIT_RUN_PATH, 0755); err != nil {
+		return err
+	}
+	rebootMode := os.FileMode(0)
+	if c.Action == "reboot" {
+		rebootMode = 0100
+	}
+	controls := []struct {
+		name string
+		mode os.FileMode
+	}{{"reboot", rebootMode}, {"stopit", 0100}}
+	for _, control := range controls {
+		path := filepath.Join(RUNIT_RUN_PATH, control.name)
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		file.Close()
+		if err := os.Chmod(path, control.mode); err != nil {
+			return err
+		}
+	}
+	return syscall.Kill(1, syscall.SIGCONT)
+}
+
+// Sends a message to all logged in users
+func (c *SystemPowerCommand) broadcast(message string) {
+	cmd := exec.Command(WALL_PATH)
+	cmd.Stdin = bytes.NewBufferString(message + "\n")
+	if c.ExecuteCommand(cmd) != 0 {
+		c.debug("Failed to broadcast message to logged in users")
+	}
+}
+
+// Parses `now`, `+MINUTES` or `HH:MM` (next occurrence)
+func parsePowerTime(value string, now time.Time) (time.Time, error) {
+	switch {
+	case value == "now":
+		return now, nil
+	case strings.HasPrefix(value, "+"):
+		minutes, err := strconv.Atoi(value[1:])
+		if err != nil || minutes < 0 {
+			return now, fmt.Errorf("Invalid time `%s`", value)
+		}
+		return now.Add(time.Duration(minutes) * time.Minute), nil
+	}
+	clock, err := time.ParseInLocation("15:04", value, now.Location())
+	if err != nil {
+		return now, fmt.Errorf("Invalid time `%s` (expected `now`, `+MINUTES` or `HH:MM`)", value)
+	}
+	when := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !when.After(now) {
+		when = when.AddDate(0, 0, 1)
+	}
+	return when, nil
+}
+
+// Parses a comma separated `NAME[:SECONDS]` service list
+func parsePowerStops(value string) ([]powerStop, error) {
+	stops := []powerStop{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		stop := powerStop{Name: item, Timeout: POWER_STOP_TIMEOUT}
+		if idx := strings.Index(item, ":"); idx != -1 {
+			timeout, err := strconv.Atoi(item[idx+1:])
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("Invalid service timeout `%s`", item)
+			}
+			stop.Name, stop.Timeout = item[:idx], timeout
+		}
+		stops = append(stops, stop)
+	}
+	return stops, nil
+}
@@ -0,0 +1,54 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type SystemTimezoneSetCommand struct {
+	SystemCommand
+}
+
+func (c *SystemTimezoneSetCommand) Run(args []string) int {
+	exitCode := 1
+	if !c.isRoot() {
+		c.UI.Error("This command must be run as `root`!")
+		return exitCode
+	}
+	cOpts, err := c.Init(args)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to setup system command: %s", err))
+		return exitCode
+	}
+	if len(cOpts.Args) != 1 {
+		c.UI.Error("Single timezone required!")
+		return exitCode
+	}
+	timezone := cOpts.Args[0]
+	if err := validateTimezone(c.RootDir, timezone); err != nil {
+		c.UI.Error(err.Error())
+		return exitCode
+	}
+	// The link target is absolute within the configured root
+	link := filepath.Join(c.RootDir, LOCALTIME_PATH)
+	tmp := link + ".void-new"
+	os.Remove(tmp)
+	if err := os.Symlink(filepath.Join(ZONEINFO_PATH, timezone), tmp); err != nil {
+		c.UI.Error(fmt.Sprintf(
+			"Failed to link %s: %s", LOCALTIME_PATH, err))
+		return exitCode
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		c.UI.Error(fmt.Sprintf(
+			"Failed to link %s: %s", LOCALTIME_PATH, err))
+		return exitCode
+	}
+	if code := c.syncRcConf("TIMEZONE", timezone); code != 0 {
+		return code
+	}
+	c.UI.Info(fmt.Sprintf("System timezone set to: %s", timezone))
+	return 0
+}
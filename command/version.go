@@ -0,0 +1,197 @@
+package command
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Single comparison of a dependency constraint (`>=1.2_1`)
+type VersionCheck struct {
+	Op      string `json:"op"`
+	Version string `json:"version"`
+}
+
+// Parsed dependency pattern. Patterns are either version constraints
+// (`foo>=1.2_1<2.0_1`), pkgvers (`foo-1.2_1`) or pkgver globs
+// (`foo-[0-9]*`).
+type DepConstraint struct {
+	Pattern string         `json:"pattern"`
+	Name    string         `json:"name"`
+	Checks  []VersionCheck `json:"checks,omitempty"`
+}
+
+func parseDepPattern(pattern string) DepConstraint {
+	dep := DepConstraint{Pattern: pattern, Name: depPatternName(pattern)}
+	rest := strings.TrimPrefix(pattern, dep.Name)
+	if strings.HasPrefix(rest, "-") {
+		dep.Checks = append(dep.Checks, VersionCheck{Op: "=", Version: rest[1:]})
+		return dep
+	}
+	for rest != "" {
+		op := rest[:1]
+		if len(rest) > 1 && rest[1] == '=' {
+			op = rest[:2]
+		}
+		rest = rest[len(op):]
+		end := strings.IndexAny(rest, "<>")
+		if end == -1 {
+			end = len(rest)
+		}
+		dep.Checks = append(dep.Checks, VersionCheck{Op: op, Version: rest[:end]})
+		rest = rest[end:]
+	}
+	return dep
+}
+
+// Constraint without the package name, or `*` when unconstrained
+func (d DepConstraint) Constraint() string {
+	if len(d.Checks) == 0 {
+		return "*"
+	}
+	parts := []string{}
+	for _, check := range d.Checks {
+		parts = append(parts, check.Op+check.Version)
+	}
+	return strings.Join(parts, "")
+}
+
+// Checks if the given version satisfies all constraint checks
+func (d DepConstraint) Match(version string) bool {
+	for _, check := range d.Checks {
+		if check.Op == "=" && strings.ContainsAny(check.Version, "*?[") {
+			if ok, _ := path.Match(check.Version, version); !ok {
+				return false
+			}
+			continue
+		}
+		cmp := compareVersions(version, check.Version)
+		switch check.Op {
+		case "=":
+			if cmp != 0 {
+				return false
+			}
+		case ">=":
+			if cmp < 0 {
+				return false
+			}
+		case "<=":
+			if cmp > 0 {
+				return false
+			}
+		case ">":
+			if cmp <= 0 {
+				return false
+			}
+		case "<":
+			if cmp >= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Compares two xbps versions (`1.2.3_1`), returning -1, 0 or 1. This
+// follows the dewey comparison used by xbps: numeric components compare
+// numerically, `alpha`, `beta`, `pre` and `rc` sort before the release
+// they are attached to (`1.0rc1` is older than `1.0`), any other letter
+// sorts after it (`1.1.1w` is newer than `1.1.1`) and the revision is
+// compared last.
+func compareVersions(a string, b string) int {
+	aVer, aRev := splitRevision(a)
+	bVer, bRev := splitRevision(b)
+	if cmp := compareVersionComponents(versionComponents(aVer), versionComponents(bVer)); cmp != 0 {
+		return cmp
+	}
+	switch {
+	case aRev < bRev:
+		return -1
+	case aRev > bRev:
+		return 1
+	}
+	return 0
+}
+
+func splitRevision(version string) (string, int) {
+	idx := strings.LastIndex(version, "_")
+	if idx == -1 {
+		return version, 0
+	}
+	rev, err := strconv.Atoi(version[idx+1:])
+	if err != nil {
+		return version, 0
+	}
+	return version[:idx], rev
+}
+
+// Dewey modifiers and their component values. `pl` and `.` separate
+// components like a dot.
+var versionModifiers = []struct {
+	Text  string
+	Value int
+}{
+	{"alpha", -3},
+	{"beta", -2},
+	{"pre", -1},
+	{"rc", -1},
+	{"pl", 0},
+	{".", 0}}
+
+// Splits a version into dewey components. Numbers are kept as is,
+// modifiers map to their values and any other letter becomes a dot
+// followed by its position in the alphabet.
+func versionComponents(version string) []int {
+	components := []int{}
+	lower := strings.ToLower(version)
+	for idx := 0; idx < len(lower); {
+		r := lower[idx]
+		if r >= '0' && r <= '9' {
+			end := idx
+			for end < len(lower) && lower[end] >= '0' && lower[end] <= '9' {
+				end++
+			}
+			n, _ := strconv.Atoi(lower[idx:end])
+			components = append(components, n)
+			idx = end
+			continue
+		}
+		matched := false
+		for _, modifier := range versionModifiers {
+			if strings.HasPrefix(lower[idx:], modifier.Text) {
+				components = append(components, modifier.Value)
+				idx += len(modifier.Text)
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		if r >= 'a' && r <= 'z' {
+			components = append(components, 0, int(r-'a')+1)
+		}
+		idx++
+	}
+	return components
+}
+
+// Missing components count as zero
+func compareVersionComponents(a []int, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
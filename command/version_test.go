@@ -0,0 +1,57 @@
+package command
+
+import (
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		result int
+	}{
+		{"1.0_1", "1.0_1", 0},
+		{"1.0_1", "1.0_2", -1},
+		{"1.10_1", "1.9_1", 1},
+		{"1.1.1w_1", "1.1.1_1", 1},
+		{"1.1.1w_1", "1.1.1v_1", 1},
+		{"1.0a_1", "1.0_1", 1},
+		{"1.0rc1_1", "1.0_1", -1},
+		{"1.0alpha_1", "1.0beta_1", -1},
+		{"1.0beta2_1", "1.0rc1_1", -1},
+		{"1.0pre1_1", "1.0_1", -1},
+		{"1.0pl1_1", "1.0_1", 1},
+		{"1.0_1", "1.0.0_1", 0},
+		{"2.0_1", "1.99z_9", 1},
+	}
+	for _, tc := range cases {
+		if result := compareVersions(tc.a, tc.b); result != tc.result {
+			t.Errorf("compareVersions(%q, %q) = %d, expected %d", tc.a, tc.b, result, tc.result)
+		}
+		if result := compareVersions(tc.b, tc.a); result != -tc.result {
+			t.Errorf("compareVersions(%q, %q) = %d, expected %d", tc.b, tc.a, result, -tc.result)
+		}
+	}
+}
+
+func TestDepConstraintMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		version string
+		match   bool
+	}{
+		{"openssl>=1.1.1_1", "1.1.1w_1", true},
+		{"openssl>=1.1.1_1", "1.1.0l_1", false},
+		{"foo>=1.0_1<2.0_1", "1.5_1", true},
+		{"foo>=1.0_1<2.0_1", "2.0_1", false},
+		{"foo<1.0_1", "1.0rc1_1", true},
+		{"foo-1.0_1", "1.0_1", true},
+		{"foo-[0-9]*", "1.2_1", true},
+	}
+	for _, tc := range cases {
+		dep := parseDepPattern(tc.pattern)
+		if match := dep.Match(tc.version); match != tc.match {
+			t.Errorf("parseDepPattern(%q).Match(%q) = %t, expected %t", tc.pattern, tc.version, match, tc.match)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package command
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const XBPS_SYSCONF_PATH = "/etc/xbps.d"
+const XBPS_SHARE_PATH = "/usr/share/xbps.d"
+const XBPS_CACHE_PATH = "/var/cache/xbps"
+
+// Single `key=value` entry from an xbps.d configuration file
+type XbpsConfEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	// Entry is commented out (only tracked for repositories)
+	Disabled bool `json:"disabled"`
+}
+
+// Effective xbps configuration built from the xbps.d directories
+type XbpsConfig struct {
+	RootDir   string
+	Files     []string
+	Overrides map[string]string
+	Entries   []XbpsConfEntry
+}
+
+// Loads the effective xbps configuration. Files in the system
+// configuration directory override files of the same name in the
+// shared directory, and all files are processed in lexical order.
+func LoadXbpsConfig(rootDir string) (*XbpsConfig, error) {
+	config := &XbpsConfig{
+		RootDir:   rootDir,
+		Overrides: map[string]string{}}
+	files := map[string]string{}
+	for _, dir := range []string{XBPS_SHARE_PATH, XBPS_SYSCONF_PATH} {
+		matches, err := filepath.Glob(filepath.Join(rootDir, dir, "*.conf"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matches {
+			if shadowed, ok := files[filepath.Base(path)]; ok {
+				config.Overrides[path] = shadowed
+			}
+			files[filepath.Base(path)] = path
+		}
+	}
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entries, err := parseXbpsConfFile(files[name])
+		if err != nil {
+			return nil, err
+		}
+		config.Files = append(config.Files, files[name])
+		config.Entries = append(config.Entries, entries...)
+	}
+	return config, nil
+}
+
+func parseXbpsConfFile(path string) ([]XbpsConfEntry, error) {
+	entries := []XbpsConfEntry{}
+	file, err := os.Open(path)
+	if err != nil {
+		return entries, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		disabled := false
+		if commented := disabledRepoLine(line); commented != "" {
+			line = commented
+			disabled = true
+		}
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		entries = append(entries, XbpsConfEntry{
+			Key:      strings.TrimSpace(parts[0]),
+			Value:    strings.TrimSpace(parts[1]),
+			File:     path,
+			Line:     lineNo,
+			Disabled: disabled})
+	}
+	return entries, scanner.Err()
+}
+
+// Returns the repository line without its comment marker if the line
+// is a commented out repository entry
+func disabledRepoLine(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "#") {
+		return ""
+	}
+	trimmed = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+	parts := strings.SplitN(trimmed, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) != "repository" {
+		return ""
+	}
+	if strings.ContainsAny(strings.TrimSpace(parts[1]), " \t") {
+		return ""
+	}
+	return trimmed
+}
+
+// All values configured for the given key, in order
+func (x *XbpsConfig) Values(key string) []XbpsConfEntry {
+	entries := []XbpsConfEntry{}
+	for _, entry := range x.Entries {
+		if entry.Key == key && !entry.Disabled {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Enabled and disabled repository entries, in order
+func (x *XbpsConfig) RepositoryEntries() []XbpsConfEntry {
+	entries := []XbpsConfEntry{}
+	for _, entry := range x.Entries {
+		if entry.Key == "repository" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Configured repositories, in order of preference
+func (x *XbpsConfig) Repositories() []XbpsConfEntry {
+	return x.Values("repository")
+}
+
+// Target architecture from the environment or configuration, falling
+// back to the native architecture
+func (x *XbpsConfig) Architecture() string {
+	if arch := os.Getenv("XBPS_ARCH"); arch != "" {
+		return arch
+	}
+	archs := x.Values("architecture")
+	if len(archs) > 0 {
+		return archs[len(archs)-1].Value
+	}
+	return x.NativeArchitecture()
+}
+
+// Native architecture as determined by xbps: the machine name with a
+// `-musl` suffix when the root directory uses musl libc
+func (x *XbpsConfig) NativeArchitecture() string {
+	output, err := exec.Command("uname", "-m").Output()
+	if err != nil {
+		return ""
+	}
+	arch := strings.TrimSpace(string(output))
+	for _, dir := range []string{"/usr/lib", "/lib"} {
+		if loaders, _ := filepath.Glob(filepath.Join(x.RootDir, dir, "ld-musl-*.so.1")); len(loaders) > 0 {
+			return arch + "-musl"
+		}
+	}
+	return arch
+}
+
+// Package cache directory below the root directory
+func (x *XbpsConfig) CacheDir() string {
+	dirs := x.Values("cachedir")
+	if len(dirs) > 0 {
+		return filepath.Join(x.RootDir, dirs[len(dirs)-1].Value)
+	}
+	return filepath.Join(x.RootDir, XBPS_CACHE_PATH)
+}
+
+// Path of the system configuration file with the given name
+func (x *XbpsConfig) SysconfPath(name string) string {
+	return filepath.Join(x.RootDir, XBPS_SYSCONF_PATH, name)
+}
+
+// Path of the shared configuration file with the given name
+func (x *XbpsConfig) SharePath(name string) string {
+	return filepath.Join(x.RootDir, XBPS_SHARE_PATH, name)
+}
+
+// Applies the edit function to every line of the given configuration
+// file, each line being replaced by the returned lines. Shared files
+// are never modified; an override of the same name is written to the
+// system configuration directory instead. Overrides ending up
+// identical to the shared file, and system files left without
+// content, are removed. Returns the path written.
+func (x *XbpsConfig) EditFile(path string, edit func(lineNo int, line string) []string) (string, error) {
+	name := filepath.Base(path)
+	dest := x.SysconfPath(name)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return dest, err
+	}
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	result := []string{}
+	for i, line := range lines {
+		result = append(result, edit(i+1, line)...)
+	}
+	return dest, x.writeConfFile(name, result)
+}
+
+// Writes a system configuration file, removing it when it would be
+// redundant. Shared files of the same name are masked as needed.
+func (x *XbpsConfig) writeConfFile(name string, lines []string) error {
+	dest := x.SysconfPath(name)
+	newContent := strings.Join(lines, "\n") + "\n"
+	shared, err := ioutil.ReadFile(x.SharePath(name))
+	hasShared := err == nil
+	empty := true
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			empty = false
+			break
+		}
+	}
+	if (hasShared && string(shared) == newContent) || (!hasShared && empty) {
+		if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(dest, []byte(newContent), 0644)
+}
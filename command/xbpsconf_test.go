@@ -0,0 +1,37 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchitecture(t *testing.T) {
+	root, err := ioutil.TempDir("", "void-xbpsconf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	os.Unsetenv("XBPS_ARCH")
+	config := &XbpsConfig{RootDir: root}
+	native := config.Architecture()
+	if native == "" || strings.HasSuffix(native, "-musl") {
+		t.Errorf("Architecture() of glibc root = %q", native)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "usr/lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "usr/lib/ld-musl-"+native+".so.1"), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if arch := config.Architecture(); arch != native+"-musl" {
+		t.Errorf("Architecture() of musl root = %q, expected %q", arch, native+"-musl")
+	}
+	os.Setenv("XBPS_ARCH", "aarch64")
+	defer os.Unsetenv("XBPS_ARCH")
+	if arch := config.Architecture(); arch != "aarch64" {
+		t.Errorf("Architecture() with XBPS_ARCH = %q, expected aarch64", arch)
+	}
+}
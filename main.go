@@ -18,7 +18,7 @@ func main() {
 }
 
 func realMain() int {
-	baseUi := &cli.BasicUi{Writer: os.Stdout, ErrorWriter: os.Stderr}
+	baseUi := &cli.BasicUi{Reader: os.Stdin, Writer: os.Stdout, ErrorWriter: os.Stderr}
 	ui := &cli.ColoredUi{
 		ErrorColor:  cli.UiColorRed,
 		InfoColor:   cli.UiColorGreen,